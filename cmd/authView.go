@@ -19,12 +19,16 @@ type AuthView struct {
 	focusIndex       int
 	errorMsg         string
 	isAuthenticating bool
+	status           string // Retries while authenticating
+	statusChan       chan string
+	listening        bool
 }
 
 // Message types
 type loginSuccessMsg struct{}
 type loginErrMsg struct{ err error }
 type pasteMsg struct{ text string }
+type authStatusMsg string
 
 var (
 	titleStyle = lipgloss.NewStyle().
@@ -66,6 +70,7 @@ func NewAuthView() AuthView {
 		height:     24,
 		token:      "",
 		focusIndex: 0,
+		statusChan: make(chan string),
 	}
 }
 
@@ -73,9 +78,11 @@ func (a AuthView) Init() tea.Cmd {
 	return tea.EnterAltScreen
 }
 
-func performLogin(token string, addGitCredential bool) tea.Cmd {
+func performLogin(token string, addGitCredential bool, statusChan chan string) tea.Cmd {
 	return func() tea.Msg {
-		err := cli.Login(token, addGitCredential)
+		err := cli.Login(token, addGitCredential, func(status string) {
+			statusChan <- status
+		})
 		if err != nil {
 			return loginErrMsg{err}
 		}
@@ -83,6 +90,12 @@ func performLogin(token string, addGitCredential bool) tea.Cmd {
 	}
 }
 
+func listenForAuthStatus(ch chan string) tea.Cmd {
+	return func() tea.Msg {
+		return authStatusMsg(<-ch)
+	}
+}
+
 func (a AuthView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -92,13 +105,19 @@ func (a AuthView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case loginSuccessMsg:
 		a.isAuthenticating = false
+		a.status = ""
 		return a, nil
 
 	case loginErrMsg:
 		a.isAuthenticating = false
+		a.status = ""
 		a.errorMsg = msg.err.Error()
 		return a, nil
 
+	case authStatusMsg:
+		a.status = string(msg)
+		return a, listenForAuthStatus(a.statusChan)
+
 	case pasteMsg:
 		if a.focusIndex == 1 {
 			a.token = msg.text
@@ -145,7 +164,12 @@ func (a AuthView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "enter":
 			if len(a.token) > 0 {
 				a.isAuthenticating = true
-				return a, performLogin(a.token, a.selected == 0)
+				cmds := []tea.Cmd{performLogin(a.token, a.selected == 0, a.statusChan)}
+				if !a.listening {
+					a.listening = true
+					cmds = append(cmds, listenForAuthStatus(a.statusChan))
+				}
+				return a, tea.Batch(cmds...)
 			} else if a.focusIndex == 0 && a.selected == 0 {
 				a.focusIndex = 1
 			}
@@ -181,7 +205,7 @@ func (a AuthView) View() string {
 
 	// Main content
 	content := "\n\nTo log in, 'huggingface_hub' requires a token\ngenerated from:\n"
-	content += linkStyle.Render("https://huggingface.co/settings/tokens")
+	content += linkStyle.Render(cli.Endpoint() + "/settings/tokens")
 	content += "\n\nWould you like to add token as git credential??\n\n"
 
 	// Options
@@ -213,6 +237,9 @@ func (a AuthView) View() string {
 	// Authentication status
 	if a.isAuthenticating {
 		content += "\n\nAuthenticating..."
+		if a.status != "" {
+			content += "\n" + helpStyle.Render(a.status)
+		}
 	}
 
 	// Calculate available space
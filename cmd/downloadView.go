@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"Lazyface/internal/cli"
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -15,36 +17,95 @@ type state int
 
 const (
 	inputRepo state = iota
+	loadingRepo
+	selectRevision
+	gatedAccess
 	selectFiles
 	selectDownloadPath
 	confirmation
 	downloading
+	queuePanel
+	inputVerify
+	verifying
+	verifyResults
+	inputMirror
+	planningMirror
+	mirrorDiff
 )
 
 const (
-	filesPerColumn    = 10 //Number of files per column
-	maxVisibleColumns = 3
+	maxConcurrency = 16 //Upper bound for parallel file downloads
+
+	maxVisibleTransfers = 10 //Files listed while downloading
 )
 
+// repoTypes are the kinds of repo the Download view can browse, in the
+// order Ctrl+T cycles through them.
+var repoTypes = []string{"model", "dataset", "space"}
+
 type statusMsg string
-type progressMsg float64
+type progressMsg cli.DownloadProgress
+type downloadDoneMsg struct {
+	job *cli.DownloadJob
+	err error
+}
+type resumeQueueMsg struct{}
 
 // Define the model structure
 type model struct {
 	state              state
+	repoType           string
 	repoInput          textinput.Model
 	files              []string
 	selectedFiles      []string
 	checked            map[int]bool
-	cursorIndex        int
-	scrollOffset       int
+	repoFiles          []cli.RepoFile
+	tree               *treeNode
+	treeCursor         int
 	downloadPathChoice string
 	path               string
 	customPathInput    textinput.Model
 	status             string
 	statusChan         chan string
-	progressChan       chan float64
+	progressChan       chan cli.DownloadProgress
 	progress           progress.Model
+	transfer           cli.DownloadProgress
+	downloadDone       bool
+	listening          bool
+	cancelDownload     context.CancelFunc
+	control            *cli.DownloadControl
+	transferSelected   string // File under the cursor while downloading
+	discardPartials    bool
+	concurrency        int
+	verifyPathInput    textinput.Model
+	verifyRevInput     textinput.Model
+	verifyField        int
+	verifyResults      []cli.VerifyResult
+	queue              *cli.DownloadQueue
+	activeJob          *cli.DownloadJob
+	queueCursor        int
+	returnState        state
+	loadingFrom        state // Where loadingRepo returns to on errors
+	loadID             int   // Tells answers of the current load from stale ones
+	revisions          []revisionOption
+	revisionCursor     int
+	revisionInput      textinput.Model
+	revision           string
+	commitSHA          string
+	gate               gateForm
+	filterInput        textinput.Model
+	filtering          bool
+	filter             string
+	savedFilters       []string
+	savedCursor        int
+	space              cli.SpaceCheck
+	spaceErr           error
+	editingBudget      bool
+	budgetInput        textinput.Model
+	syncPlan           *cli.SyncPlan
+	mirrorPlan         *cli.MirrorPlan
+	mirrorDelete       bool
+	rate               rateEditor
 }
 
 // Initialize the model
@@ -56,20 +117,53 @@ func InitialDownloadModel() model {
 	customPathInput := textinput.New()
 	customPathInput.Placeholder = "Enter custom path"
 
+	verifyPathInput := textinput.New()
+	verifyPathInput.Placeholder = "Local folder"
+
+	verifyRevInput := textinput.New()
+	verifyRevInput.Placeholder = cli.DefaultRevision
+
+	filterInput := textinput.New()
+	filterInput.Placeholder = "*.safetensors !*onnx* tokenizer*"
+
+	budgetInput := textinput.New()
+	budgetInput.Placeholder = "no limit"
+
+	revisionInput := textinput.New()
+	revisionInput.Placeholder = cli.DefaultRevision
+
+	queue, err := cli.LoadQueue()
+	if err != nil {
+		queue = &cli.DownloadQueue{}
+	}
+
 	return model{
 		state:           inputRepo,
+		repoType:        repoTypes[0],
 		repoInput:       ti,
 		checked:         make(map[int]bool),
 		customPathInput: customPathInput,
 		status:          "Ready to download.",
 		statusChan:      make(chan string),
-		progressChan:    make(chan float64),
+		progressChan:    make(chan cli.DownloadProgress),
 		progress:        progress.New(progress.WithScaledGradient("#fd5392", "#f86f64"), progress.WithWidth(80)),
+		concurrency:     cli.DefaultConcurrency,
+		verifyPathInput: verifyPathInput,
+		verifyRevInput:  verifyRevInput,
+		revisionInput:   revisionInput,
+		filterInput:     filterInput,
+		budgetInput:     budgetInput,
+		rate:            newRateEditor(),
+		queue:           queue,
+		downloadDone:    true,
 	}
 }
 
-// Init function (optional)
+// Init resumes jobs left in the queue by a previous run
 func (m model) Init() tea.Cmd {
+	if m.queue.Pending() > 0 {
+		return tea.Batch(textinput.Blink, func() tea.Msg { return resumeQueueMsg{} })
+	}
 	return textinput.Blink
 }
 
@@ -79,56 +173,175 @@ func listenForStatus(ch chan string) tea.Cmd {
 	}
 }
 
-func listenForProgress(ch chan float64) tea.Cmd {
+func listenForProgress(ch chan cli.DownloadProgress) tea.Cmd {
 	return func() tea.Msg {
 		return progressMsg(<-ch)
 	}
 }
 
+// Busy reports whether a download or queued job is running.
+func (m model) Busy() bool {
+	return !m.downloadDone
+}
+
+// startJob runs cli.Download for job in the background and streams its
+// status and progress back into the model.
+func (m *model) startJob(job *cli.DownloadJob) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelDownload = cancel
+	m.control = cli.NewDownloadControl()
+	m.downloadDone = false
+	m.transfer = cli.DownloadProgress{}
+	m.transferSelected = ""
+	m.discardPartials = false
+
+	m.activeJob = job
+	job.Status = cli.JobRunning
+	job.Error = ""
+	m.saveQueue()
+
+	repoID, files, path := job.RepoID, job.Files, job.Path
+	opts := cli.DownloadOptions{
+		RepoType:    job.RepoType,
+		Revision:    job.Revision,
+		Filter:      cli.ParseFilter(job.Filter),
+		Concurrency: m.concurrency,
+		Control:     m.control,
+		CacheLayout: job.Cache,
+		Ref:         job.Ref,
+	}
+	statusChan, progressChan := m.statusChan, m.progressChan
+
+	run := func() tea.Msg {
+		err := cli.Download(ctx, repoID, files, path, opts,
+			func(status string) {
+				statusChan <- status
+			},
+			func(progress cli.DownloadProgress) {
+				progressChan <- progress
+			})
+		return downloadDoneMsg{job: job, err: err}
+	}
+	return tea.Batch(run, m.listen())
+}
+
+// enqueue adds job to the queue and starts it right away when nothing else
+// is downloading.
+func (m *model) enqueue(job *cli.DownloadJob) tea.Cmd {
+	m.queue.Add(job)
+	m.saveQueue()
+	if m.downloadDone {
+		m.state = downloading
+		return m.startJob(job)
+	}
+	m.status = fmt.Sprintf("Added %s to the queue (%d waiting).", job.RepoID, m.queue.Pending())
+	return nil
+}
+
+// runNext starts the next queued job, if any, once the current one ended.
+func (m *model) runNext() tea.Cmd {
+	if !m.downloadDone {
+		return nil
+	}
+	if next := m.queue.Next(); next != nil {
+		return m.startJob(next)
+	}
+	return nil
+}
+
+// cacheFolder shows where the repo lands in the huggingface_hub cache.
+func (m model) cacheFolder() string {
+	dir, err := cli.RepoCacheDir(m.repoType, m.repoInput.Value())
+	if err != nil {
+		return err.Error()
+	}
+	return dir
+}
+
+func (m *model) saveQueue() {
+	if err := cli.SaveQueue(m.queue); err != nil {
+		m.status = fmt.Sprintf("Error saving queue: %v", err)
+	}
+}
+
+// listen arms the status and progress listeners once; each re-arms itself
+// after delivering a message.
+func (m *model) listen() tea.Cmd {
+	if m.listening {
+		return nil
+	}
+	m.listening = true
+	return tea.Batch(
+		listenForStatus(m.statusChan),
+		listenForProgress(m.progressChan),
+	)
+}
+
 // Handle user input
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if msg.String() == "ctrl+o" && m.state != queuePanel {
+			m.returnState = m.state
+			m.state = queuePanel
+			return m, nil
+		}
+
+		if m.state == selectFiles && m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.state == confirmation && m.editingBudget {
+			return m.updateBudget(msg)
+		}
+		if m.state == downloading && m.rate.editing {
+			cmd, err := m.rate.update(msg, m.control.SetRateLimit)
+			if err != nil {
+				m.status = fmt.Sprintf("Error: %v", err)
+			}
+			return m, cmd
+		}
+
+		switch m.state {
+		case inputRepo:
+			return m.updateRepoInput(msg)
+		case selectFiles:
+			// Keys the tree does not use fall through to the ones below
+			if updated, cmd, handled := m.updateTree(msg); handled {
+				return updated, cmd
+			}
+		case queuePanel:
+			return m.updateQueue(msg)
+		case loadingRepo:
+			return m.updateLoading(msg)
+		case selectRevision:
+			return m.updateRevision(msg)
+		case gatedAccess:
+			return m.updateGate(msg)
+		case inputVerify, verifyResults:
+			return m.updateVerify(msg)
+		case inputMirror, mirrorDiff:
+			return m.updateMirror(msg)
+		case downloading:
+			return m.updateDownloading(msg)
+		}
+
 		switch msg.String() {
 		case "q": // Quit the program
 			return m, tea.Quit
 
 		case "enter": // Print input and quit
-			if m.state == inputRepo {
-				repoID := m.repoInput.Value()
-				files, err := cli.ListRepoFiles(repoID)
-				if err != nil {
-					return m, nil
-				}
-				m.files = files
-				m.state = selectFiles
-				m.cursorIndex = 0
-				m.scrollOffset = 0
-				return m, nil
-			} else if m.state == selectFiles {
-				selectedFiles := []string{}
-				for i, file := range m.files {
-					if m.checked[i] {
-						selectedFiles = append(selectedFiles, file)
-					}
-				}
-				m.selectedFiles = selectedFiles
-				if len(selectedFiles) > 0 {
-					m.state = selectDownloadPath
-				}
-				return m, nil
-			} else if m.state == selectDownloadPath {
+			if m.state == selectDownloadPath {
 				var path string
 				var err error
 				if m.downloadPathChoice == "custom" {
 					if m.customPathInput.Value() == "" {
 						return m, nil
 					}
-					path, err = cli.GetDownloadPath("custom", m.customPathInput.Value(), m.repoInput.Value())
+					path, err = cli.GetDownloadPath("custom", m.customPathInput.Value(), m.repoType, m.repoInput.Value())
 				} else {
-					path, err = cli.GetDownloadPath(m.downloadPathChoice, "", m.repoInput.Value())
+					path, err = cli.GetDownloadPath(m.downloadPathChoice, "", m.repoType, m.repoInput.Value())
 				}
 
 				if err != nil {
@@ -136,122 +349,161 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				m.path = path
+				cmd := m.planSync()
+				m.checkSpace()
+				m.status = ""
 				m.state = confirmation
-				return m, nil
+				return m, cmd
 			} else if m.state == confirmation {
-				m.state = downloading
-				go func() {
-					err := cli.Download(
-						m.repoInput.Value(),
-						m.selectedFiles,
-						m.path,
-						func(status string) {
-							m.statusChan <- status
-						},
-						func(progress float64) {
-							m.progressChan <- progress
-						})
-					if err != nil {
-						m.statusChan <- fmt.Sprintf("Error: %v", err)
-					}
-					m.statusChan <- "Download complete"
-				}()
-				return m, tea.Batch(
-					listenForStatus(m.statusChan),
-					listenForProgress(m.progressChan),
-				)
-			}
-
-		case "a": //Toggle select all
-			allSelected := len(m.checked) == len(m.files)
-			m.checked = make(map[int]bool)
-			if !allSelected {
-				for i := range m.files {
-					m.checked[i] = true
+				// Without the free space the budget still applies
+				err := m.space.BudgetExceeded()
+				if m.spaceErr == nil {
+					err = m.space.Blocked()
 				}
+				if err != nil {
+					m.status = fmt.Sprintf("Error: %v", err)
+					return m, nil
+				}
+				revision := m.commitSHA
+				if revision == "" {
+					revision = m.revision
+				}
+				job := cli.NewDownloadJob(m.repoType, m.repoInput.Value(), revision, m.selectedFiles, m.path)
+				job.Filter = m.jobFilter()
+				job.Ref = m.revision
+				job.Cache = m.downloadPathChoice == "HF Cache"
+				cmd := m.enqueue(job)
+				if m.state != downloading {
+					m.state = queuePanel
+					m.returnState = inputRepo
+				}
+				return m, cmd
 			}
-			return m, nil
 
-		case " ":
-			if m.state == selectFiles && m.cursorIndex < len(m.files) {
-				m.checked[m.cursorIndex] = !m.checked[m.cursorIndex]
+		case "b":
+			if m.state == confirmation {
+				return m.openBudget()
 			}
-			return m, nil
 
-			// Left movement (move one column left)
-		case "left":
-			if (m.cursorIndex-filesPerColumn) >= 0 && (m.cursorIndex/filesPerColumn) > m.scrollOffset {
-				m.cursorIndex -= filesPerColumn
+		// Adjust how many files are fetched in parallel
+		case "+":
+			if m.state == confirmation {
+				if m.concurrency < maxConcurrency {
+					m.concurrency++
+				}
+				return m, nil
 			}
-			return m, nil
 
-		// Right movement (move one column right)
-		case "right":
-			if (m.cursorIndex+filesPerColumn) < len(m.files) &&
-				(m.cursorIndex/filesPerColumn) < (m.scrollOffset+maxVisibleColumns-1) {
-				m.cursorIndex += filesPerColumn
+		case "-":
+			if m.state == confirmation {
+				if m.concurrency > 1 {
+					m.concurrency--
+				}
+				return m, nil
 			}
-			return m, nil
 
-		// Move cursor up within a column
-		case "up":
-			if (m.cursorIndex % filesPerColumn) > 0 {
-				m.cursorIndex--
+		case "1", "2", "3", "4":
+			if m.state != selectDownloadPath {
+				return m, nil
 			}
-			return m, nil
-
-		// Move cursor down within a column
-		case "down":
-			if (m.cursorIndex%filesPerColumn) < (filesPerColumn-1) && (m.cursorIndex+1) < len(m.files) {
-				m.cursorIndex++
+			switch msg.String() {
+			case "1":
+				m.downloadPathChoice = "Downloads"
+			case "2":
+				m.downloadPathChoice = "Current Directory"
+			case "3":
+				m.downloadPathChoice = "custom"
+				m.customPathInput.Focus()
+			case "4":
+				m.downloadPathChoice = "HF Cache"
 			}
 			return m, nil
+		}
 
-		// Scroll view left `[`
-		case "[":
-			if m.scrollOffset > 0 {
-				m.scrollOffset--
-				// Keep cursor within the visible columns
-				if (m.cursorIndex / filesPerColumn) < m.scrollOffset {
-					m.cursorIndex = m.scrollOffset * filesPerColumn
-				}
+	case downloadDoneMsg:
+		job := msg.job
+		m.downloadDone = true
+		m.cancelDownload()
+		job.Failed = nil
+		if errors.Is(msg.err, context.Canceled) {
+			job.Status = cli.JobCanceled
+			if m.discardPartials {
+				cli.RemovePartials(job.Path, job.Files, job.Cache)
+				m.status = "Download canceled, partial files removed."
+			} else {
+				m.status = "Download canceled, partial files kept to resume later."
 			}
+			m.saveQueue()
 			return m, nil
-
-		// Scroll view right `]`
-		case "]":
-			if m.scrollOffset+maxVisibleColumns < (len(m.files)+filesPerColumn-1)/filesPerColumn {
-				m.scrollOffset++
-				// Ensure cursor stays in the visible area
-				if (m.cursorIndex / filesPerColumn) >= (m.scrollOffset + maxVisibleColumns) {
-					m.cursorIndex = (m.scrollOffset + maxVisibleColumns - 1) * filesPerColumn
-					if m.cursorIndex >= len(m.files) {
-						m.cursorIndex = len(m.files) - 1
-					}
+		} else if msg.err != nil {
+			job.Status = cli.JobFailed
+			job.Error = msg.err.Error()
+			var dlErr *cli.DownloadError
+			if errors.As(msg.err, &dlErr) {
+				for _, f := range dlErr.Failed {
+					job.Failed = append(job.Failed, f.File)
 				}
 			}
-			return m, nil
+			m.status = repoErrorMessage(job.RepoType, job.RepoID, msg.err)
+			if len(job.Failed) > 0 {
+				m.status += "\n\nPress R to re-fetch failed files"
+			}
+		} else {
+			job.Status = cli.JobDone
+			m.status = "Download complete"
+		}
+		m.saveQueue()
+		return m, m.runNext()
 
-		case "1":
-			m.downloadPathChoice = "Downloads"
-			return m, nil
+	case resumeQueueMsg:
+		return m, m.runNext()
 
-		case "2":
-			m.downloadPathChoice = "Current Directory"
-			return m, nil
+	case revisionsMsg:
+		return m.revisionsLoaded(msg)
 
-		case "3":
-			m.downloadPathChoice = "custom"
-			m.customPathInput.Focus()
-			return m, nil
+	case repoFilesMsg:
+		return m.filesLoaded(msg)
+
+	case gateMsg:
+		return m.gateLoaded(msg)
+
+	case accessRequestedMsg:
+		return m.accessRequested(msg)
+
+	case mirrorPlanMsg:
+		m.state = mirrorDiff
+		m.mirrorPlan = msg.plan
+		if msg.err != nil {
+			m.status = repoErrorMessage(m.repoType, m.repoInput.Value(), msg.err)
+		} else {
+			m.status = ""
+		}
+		return m, nil
+
+	case syncPlanMsg:
+		if msg.path == m.path {
+			m.syncPlan = &msg.plan
+			m.checkSpace()
+		}
+		return m, nil
+
+	case verifyDoneMsg:
+		m.state = verifyResults
+		m.verifyResults = msg.results
+		if msg.err != nil {
+			m.status = repoErrorMessage(m.repoType, m.repoInput.Value(), msg.err)
+		} else {
+			m.status = ""
 		}
+		return m, nil
 
 	case statusMsg:
 		m.status = string(msg)
 		return m, listenForStatus(m.statusChan)
 
 	case progressMsg:
-		cmd := m.progress.SetPercent(float64(msg))
+		m.transfer = cli.DownloadProgress(msg)
+		cmd := m.progress.SetPercent(m.transfer.Percent())
 		return m, tea.Batch(cmd, listenForProgress(m.progressChan))
 
 	case progress.FrameMsg:
@@ -262,7 +514,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	}
 
-	if m.downloadPathChoice == "custom" {
+	if m.state == selectDownloadPath && m.downloadPathChoice == "custom" {
 		m.customPathInput, cmd = m.customPathInput.Update(msg)
 		return m, cmd
 	}
@@ -275,40 +527,61 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func generateColumns(files []string, checked map[int]bool, cursor int, scrollOffset int) string {
-	numColumns := (len(files) + filesPerColumn - 1) / filesPerColumn
-	visibleEnd := scrollOffset + maxVisibleColumns
-	if visibleEnd > numColumns {
-		visibleEnd = numColumns
+// repoErrorMessage explains why a repo could not be listed or downloaded.
+func repoErrorMessage(repoType, repoID string, err error) string {
+	switch {
+	case errors.Is(err, cli.ErrGatedRepo):
+		return fmt.Sprintf("%s is gated. Request access at %s, then try again.", repoID, cli.RepoURL(repoType, repoID))
+	case errors.Is(err, cli.ErrLoginRequired):
+		return fmt.Sprintf("%s is private or does not exist. Log in from the Auth tab with an account that can access it.", repoID)
+	case errors.Is(err, cli.ErrRepoNotFound):
+		return fmt.Sprintf("%s was not found. Check the name and the repo type (Ctrl+T).", repoID)
+	case errors.Is(err, cli.ErrRevisionNotFound):
+		return fmt.Sprintf("That revision does not exist in %s.", repoID)
+	case errors.Is(err, cli.ErrAccessDenied):
+		return fmt.Sprintf("Your token cannot access %s. Check it has read access and that your account was granted access.", repoID)
+	case errors.Is(err, cli.ErrRateLimited):
+		return "The Hub is rate limiting requests and retries did not get through. Wait a few minutes and try again."
+	case errors.Is(err, cli.ErrHubUnavailable):
+		return "The Hub is unavailable right now and retries did not get through. Try again later."
 	}
+	return fmt.Sprintf("Error: %v", err)
+}
 
-	columnStrings := make([]string, 0, maxVisibleColumns)
+func nextRepoType(current string) string {
+	for i, t := range repoTypes {
+		if t == current {
+			return repoTypes[(i+1)%len(repoTypes)]
+		}
+	}
+	return repoTypes[0]
+}
 
-	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
+// updateRepoInput handles keys while the repo name is typed, so letters and
+// digits reach the input instead of the file selection shortcuts.
+func (m model) updateRepoInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
 
-	for col := scrollOffset; col < visibleEnd; col++ {
-		start := col * filesPerColumn
-		end := start + filesPerColumn
-		if end > len(files) {
-			end = len(files)
+	switch msg.String() {
+	case "enter":
+		if m.repoInput.Value() == "" {
+			return m, nil
 		}
+		return m.openRevisions()
 
-		column := ""
-		for i := start; i < end; i++ {
-			checkmark := "[ ]"
-			if checked[i] {
-				checkmark = "[✓]"
-			}
-			cursorMarker := "  "
-			if i == cursor {
-				cursorMarker = cursorStyle.Render("➤")
-			}
-			column += fmt.Sprintf("%s%s %s\n", cursorMarker, checkmark, files[i])
-		}
-		columnStrings = append(columnStrings, column)
+	case "ctrl+l": // Verify a local folder against the repo
+		return m.openVerifyForm(), textinput.Blink
+
+	case "ctrl+r": // Mirror the repo into a local folder
+		return m.openMirrorForm(), textinput.Blink
+
+	case "ctrl+t": // Cycle between models, datasets and spaces
+		m.repoType = nextRepoType(m.repoType)
+		return m, nil
 	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Left, columnStrings...)
+	m.repoInput, cmd = m.repoInput.Update(msg)
+	return m, cmd
 }
 
 // Define the view
@@ -331,33 +604,68 @@ func (m model) View() string {
 	case inputRepo:
 		return lipgloss.JoinVertical(lipgloss.Top,
 			headerStyle.Render("Download"),
-			bodyStyle.Render(fmt.Sprintf("%s\n\n%s\n\n%s", "Enter Repo Name:", m.repoInput.View(), "Press Enter to confirm")),
+			bodyStyle.Render(fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", "Enter Repo Name:", m.repoInput.View(), "Repo type: "+repoStyle.Render(m.repoType)+" (Ctrl+T to change)", "Press Enter to confirm, Ctrl+L to verify a local folder, Ctrl+R to mirror into one, Ctrl+O for the download queue")),
+			bodyStyle.Render(m.status),
+		)
+
+	case loadingRepo:
+		return lipgloss.JoinVertical(lipgloss.Top,
+			headerStyle.Render(m.repoInput.Value()),
+			bodyStyle.Render(m.status+"\n\nPress B to go back"),
 		)
 
 	case selectFiles:
-		columns := generateColumns(m.files, m.checked, m.cursorIndex, m.scrollOffset)
+		selectedCount, selectedSize := m.selectionSize()
 		return lipgloss.JoinVertical(lipgloss.Top,
-			headerStyle.Render("Select Files"),
-			bodyStyle.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#f88e64")).Render(columns+fmt.Sprintf("\nPage %d/%d\n[SPACE] Toggle Select  [A] Toggle All  [←/→] Move  [↑/↓] Move  [ Scroll-Left  ] Scroll-Right  [ENTER] Confirm  ", m.scrollOffset+1, (len(m.files)+filesPerColumn-1)/filesPerColumn)),
+			headerStyle.Render(fmt.Sprintf("Select Files  •  %d of %d selected, %s of %s", selectedCount, len(m.files), cli.FormatBytes(selectedSize), cli.FormatBytes(m.tree.size))),
+			bodyStyle.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#f88e64")).Render(m.renderTree()+"\n\n[SPACE] Toggle file/folder  [A] Toggle All  [↑/↓] Move  [←/→] Collapse/Expand  [PgUp/PgDn] Page  [ENTER] Confirm  [B] Back"),
+			bodyStyle.Render(m.filterLine()),
 		)
 
 	case selectDownloadPath:
 		return lipgloss.JoinVertical(lipgloss.Top,
 			headerStyle.Render("Select Download Path"),
-			bodyStyle.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#f88e64")).Render(fmt.Sprintf("[1] Default (Downloads/hfmodels/%s)\n[2] Current-Directory (%s)", m.repoInput.Value(), m.repoInput.Value())),
+			bodyStyle.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#f88e64")).Render(fmt.Sprintf("[1] Default (Downloads/%s/%s)\n[2] Current-Directory (%s/%s)\n[4] Hugging Face cache (%s)", cli.DownloadFolder(m.repoType), m.repoInput.Value(), cli.DownloadFolder(m.repoType), m.repoInput.Value(), m.cacheFolder())),
 			bodyStyle.Render(fmt.Sprintf("Current Choice: %s\nPress ENTER to confirm", m.downloadPathChoice)),
 		)
 
 	case confirmation:
+		_, selectedSize := m.selectionSize()
 		return lipgloss.JoinVertical(lipgloss.Top,
 			headerStyle.Render("Confirmation"),
-			bodyStyle.BorderStyle(lipgloss.RoundedBorder()).Render(fmt.Sprintf("Downloading %s files from %s to %s\n\nPress Enter to start, Q to quit", fileCount, repoName, destinationPath)),
+			bodyStyle.BorderStyle(lipgloss.RoundedBorder()).Render(fmt.Sprintf("Downloading %s files from %s to %s\nTotal size: %s (%d bytes)\n%s\nRevision: %s (commit %s)\nParallel downloads: %d ([+/-] to change)\n%s\n\nPress Enter to start, Q to quit", fileCount, repoName, destinationPath, cli.FormatBytes(selectedSize), selectedSize, m.syncSummary(), m.revision, m.commitSHA, m.concurrency, m.spaceSummary())),
+			bodyStyle.Render(m.status),
 		)
 
+	case inputVerify, verifying, verifyResults:
+		return m.verifyView()
+
+	case inputMirror, planningMirror, mirrorDiff:
+		return m.mirrorView()
+
+	case selectRevision:
+		return m.revisionView()
+
+	case gatedAccess:
+		return m.gateView()
+
+	case queuePanel:
+		return m.queueView()
+
 	case downloading:
+		if m.activeJob != nil {
+			fileCount = countStyle.Render(strconv.Itoa(len(m.activeJob.Files)))
+			repoName = repoStyle.Render(m.activeJob.RepoID)
+			if m.activeJob.Revision != "" {
+				repoName += "@" + shortSHA(m.activeJob.Revision)
+			}
+			destinationPath = pathStyle.Render(m.activeJob.Path)
+		}
 		return lipgloss.JoinVertical(lipgloss.Top,
 			headerStyle.BorderStyle(lipgloss.RoundedBorder()).Render(fmt.Sprintf("Downloading %s files from %s to %s\n", fileCount, repoName, destinationPath)),
-			bodyStyle.Render(m.progress.View()),
+			bodyStyle.Render(m.progress.View()+"\n"+transferSummary(m.transfer)+m.rateLine()),
+			bodyStyle.Render(renderTransfers(m.transfer, transferIndex(transferOrder(m.transfer), m.transferSelected), "↓")),
+			bodyStyle.Render(m.transferHelp()),
 			bodyStyle.Render(m.status),
 		)
 	}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openFilter starts typing glob patterns in the file selection screen.
+func (m model) openFilter() (tea.Model, tea.Cmd) {
+	m.filtering = true
+	m.savedFilters, _ = cli.LoadSavedFilters()
+	m.savedCursor = -1
+	m.filterInput.SetValue(m.filter)
+	m.filterInput.CursorEnd()
+	m.filterInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "up", "down": // Browse saved filters
+		if len(m.savedFilters) == 0 {
+			return m, nil
+		}
+		if msg.String() == "up" && m.savedCursor < len(m.savedFilters)-1 {
+			m.savedCursor++
+		} else if msg.String() == "down" && m.savedCursor > 0 {
+			m.savedCursor--
+		}
+		if m.savedCursor >= 0 {
+			m.filterInput.SetValue(m.savedFilters[m.savedCursor])
+			m.filterInput.CursorEnd()
+		}
+		return m, nil
+
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.filter = m.filterInput.Value()
+		filter := cli.ParseFilter(m.filter)
+		if filter.Empty() {
+			return m, nil
+		}
+
+		// Include patterns select what they match, exclude patterns deselect
+		for i, file := range m.files {
+			if filter.Excluded(file) {
+				delete(m.checked, i)
+			} else if filter.Included(file) {
+				m.checked[i] = true
+			}
+		}
+		if err := cli.SaveFilter(m.filter); err != nil {
+			m.status = fmt.Sprintf("Error saving filter: %v", err)
+		}
+		return m, nil
+	}
+
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	return m, cmd
+}
+
+// filterLine renders the pattern input with its live match count, or the
+// applied filter when not typing.
+func (m model) filterLine() string {
+	if !m.filtering {
+		if m.filter == "" {
+			return "[/] Filter with glob patterns"
+		}
+		return fmt.Sprintf("Filter: %s  [/] Edit", m.filter)
+	}
+	matched := len(cli.ParseFilter(m.filterInput.Value()).Apply(m.files))
+	return fmt.Sprintf("Filter: %s\n%d of %d files match  (prefix ! to exclude, [↑/↓] saved filters, [ENTER] apply)",
+		m.filterInput.View(), matched, len(m.files))
+}
+
+// jobFilter returns the applied filter when the selection is consistent
+// with it, so the download layer can enforce and store it with the job.
+func (m model) jobFilter() string {
+	filter := cli.ParseFilter(m.filter)
+	if filter.Empty() {
+		return ""
+	}
+	for _, file := range m.selectedFiles {
+		if !filter.Match(file) {
+			return ""
+		}
+	}
+	return filter.String()
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gateForm holds the access request form of a gated repo.
+type gateForm struct {
+	info    *cli.GateInfo
+	inputs  []textinput.Model // One per field; only text-like fields use theirs
+	checked map[int]bool      // Checkbox fields
+	choice  map[int]int       // Selected option of select fields
+	field   int               // Focused field; len(fields) is the submit button
+}
+
+func newGateForm(info *cli.GateInfo) gateForm {
+	form := gateForm{
+		info:    info,
+		inputs:  make([]textinput.Model, len(info.Fields)),
+		checked: make(map[int]bool),
+		choice:  make(map[int]int),
+	}
+	for i, f := range info.Fields {
+		form.inputs[i] = textinput.New()
+		form.inputs[i].Placeholder = f.Type
+	}
+	form.focus(0)
+	return form
+}
+
+func (f *gateForm) focus(i int) {
+	for j := range f.inputs {
+		f.inputs[j].Blur()
+	}
+	f.field = i
+	if i < len(f.inputs) {
+		f.inputs[i].Focus()
+	}
+}
+
+// answers returns the form values in the shape the Hub's form expects.
+func (f gateForm) answers() map[string]string {
+	answers := make(map[string]string, len(f.info.Fields))
+	for i, field := range f.info.Fields {
+		switch field.Type {
+		case "checkbox":
+			if f.checked[i] {
+				answers[field.Name] = "on"
+			}
+		case "select":
+			if len(field.Options) > 0 {
+				answers[field.Name] = field.Options[f.choice[i]]
+			}
+		default:
+			answers[field.Name] = f.inputs[i].Value()
+		}
+	}
+	return answers
+}
+
+type gateMsg struct {
+	id   int
+	info *cli.GateInfo
+	err  error
+}
+
+type accessRequestedMsg struct {
+	id  int
+	err error
+}
+
+// missing returns the first field left empty, or -1 when all are answered.
+// The Hub requires every extra field; checkboxes have to be ticked.
+func (f gateForm) missing() int {
+	for i, field := range f.info.Fields {
+		switch field.Type {
+		case "checkbox":
+			if !f.checked[i] {
+				return i
+			}
+		case "select":
+			// One of the options is always chosen
+		default:
+			if strings.TrimSpace(f.inputs[i].Value()) == "" {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// checkGate looks up the access gate in the background once files of a
+// revision are listed; gateLoaded moves on from there. It is part of the
+// current load.
+func (m *model) checkGate() tea.Cmd {
+	id, repoType, repoID, revision, files, statusChan := m.loadID, m.repoType, m.repoInput.Value(), m.revision, m.files, m.statusChan
+	return tea.Batch(func() tea.Msg {
+		info, err := cli.RepoGate(repoType, repoID, revision, files, func(status string) {
+			statusChan <- status
+		})
+		return gateMsg{id: id, info: info, err: err}
+	}, m.listen())
+}
+
+// gateLoaded shows the file list when the user may download, the access
+// form otherwise. When the form was already shown, access was just checked
+// again and the files are listed anew.
+func (m model) gateLoaded(msg gateMsg) (tea.Model, tea.Cmd) {
+	if m.state != loadingRepo || msg.id != m.loadID {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m.loadFailed(msg.err), nil
+	}
+	refresh := m.loadingFrom == gatedAccess
+	info := msg.info
+	if info.Status != cli.AccessGranted {
+		if refresh {
+			m.gate.info.Status = info.Status
+		} else {
+			m.gate = newGateForm(info)
+		}
+		m.state = gatedAccess
+		m.status = gateStatusMessage(info.Status)
+		return m, nil
+	}
+	if !refresh {
+		m.state = selectFiles
+		m.status = "Ready to download."
+		return m, nil
+	}
+
+	// Listings of gated repos may hide files until access is granted
+	m.status = "Listing files..."
+	return m, m.listFiles(m.revision)
+}
+
+// accessRequested checks the gate again once the request went through.
+func (m model) accessRequested(msg accessRequestedMsg) (tea.Model, tea.Cmd) {
+	if m.state != loadingRepo || msg.id != m.loadID {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m.loadFailed(msg.err), nil
+	}
+	m.status = "Checking access..."
+	return m, m.checkGate()
+}
+
+func gateStatusMessage(status cli.AccessStatus) string {
+	switch status {
+	case cli.AccessPending:
+		return "Your request is awaiting a review from the repo authors. Press R to check again."
+	case cli.AccessRejected:
+		return "Your request was rejected by the repo authors."
+	case cli.AccessLoginRequired:
+		return "This repo is gated. Log in from the Auth tab to request access."
+	case cli.AccessGranted:
+		return "Access granted."
+	}
+	return "This repo is gated. Fill in the form to request access."
+}
+
+func (m model) updateGate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	form := &m.gate
+	fields := form.info.Fields
+
+	// Outside the form only refreshing and going back make sense
+	if form.info.Status != cli.AccessNotRequested {
+		switch msg.String() {
+		case "r":
+			return m.refreshGate()
+		case "b":
+			m.state = inputRepo
+			m.repoInput.Focus()
+			return m, textinput.Blink
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+b":
+		m.state = inputRepo
+		m.repoInput.Focus()
+		return m, textinput.Blink
+
+	case "up", "shift+tab":
+		if form.field > 0 {
+			form.focus(form.field - 1)
+		}
+		return m, nil
+
+	case "down":
+		if form.field < len(fields) {
+			form.focus(form.field + 1)
+		}
+		return m, nil
+
+	case "enter":
+		if form.field < len(fields) {
+			form.focus(form.field + 1)
+			return m, nil
+		}
+		if i := form.missing(); i >= 0 {
+			form.focus(i)
+			m.status = fmt.Sprintf("%s is required.", fields[i].Name)
+			return m, nil
+		}
+		m = m.startLoading("Requesting access...")
+		id, repoType, repoID, answers, statusChan := m.loadID, m.repoType, m.repoInput.Value(), form.answers(), m.statusChan
+		return m, tea.Batch(func() tea.Msg {
+			err := cli.RequestRepoAccess(repoType, repoID, answers, func(status string) {
+				statusChan <- status
+			})
+			return accessRequestedMsg{id: id, err: err}
+		}, m.listen())
+	}
+
+	if form.field < len(fields) {
+		switch fields[form.field].Type {
+		case "checkbox":
+			if msg.String() == " " {
+				form.checked[form.field] = !form.checked[form.field]
+			}
+			return m, nil
+		case "select":
+			if n := len(fields[form.field].Options); n > 0 {
+				switch msg.String() {
+				case "left":
+					form.choice[form.field] = (form.choice[form.field] + n - 1) % n
+				case "right", " ":
+					form.choice[form.field] = (form.choice[form.field] + 1) % n
+				}
+			}
+			return m, nil
+		}
+		form.inputs[form.field], cmd = form.inputs[form.field].Update(msg)
+	}
+	return m, cmd
+}
+
+// refreshGate checks the user's status again and moves on to the file list
+// once access is granted.
+func (m model) refreshGate() (tea.Model, tea.Cmd) {
+	m = m.startLoading("Checking access...")
+	return m, m.checkGate()
+}
+
+func (m model) gateView() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#fe6375")).Padding(1).Align(lipgloss.Center)
+	bodyStyle := lipgloss.NewStyle().Padding(1)
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	info := m.gate.info
+	heading := info.Heading
+	if heading == "" {
+		heading = fmt.Sprintf("You need to agree to share your contact information to access %s", m.repoInput.Value())
+	}
+
+	var body strings.Builder
+	if info.Prompt != "" {
+		body.WriteString(info.Prompt + "\n\n")
+	}
+	if info.Mode == "manual" {
+		body.WriteString(dimStyle.Render("Requests are reviewed by the repo authors.") + "\n\n")
+	}
+
+	if info.Status == cli.AccessNotRequested {
+		for i, f := range info.Fields {
+			marker := "  "
+			if i == m.gate.field {
+				marker = cursorStyle.Render("➤ ")
+			}
+			var value string
+			switch f.Type {
+			case "checkbox":
+				value = "[ ]"
+				if m.gate.checked[i] {
+					value = "[✓]"
+				}
+			case "select":
+				if len(f.Options) > 0 {
+					value = "◀ " + f.Options[m.gate.choice[i]] + " ▶"
+				}
+			default:
+				value = m.gate.inputs[i].View()
+			}
+			body.WriteString(fmt.Sprintf("%s%s: %s\n", marker, f.Name, value))
+		}
+		button := info.ButtonContent
+		if button == "" {
+			button = "Agree and request access"
+		}
+		marker := "  "
+		if m.gate.field == len(info.Fields) {
+			marker = cursorStyle.Render("➤ ")
+		}
+		body.WriteString(fmt.Sprintf("\n%s[%s]\n\n", marker, button))
+		body.WriteString("[↑/↓] Move  [SPACE] Toggle  [←/→] Choose  [ENTER] Next / Submit  [Ctrl+B] Back")
+	} else {
+		body.WriteString(fmt.Sprintf("Status: %s\n\n[R] Check again  [B] Back", info.Status))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Top,
+		headerStyle.Render("Gated Repository"),
+		bodyStyle.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#f88e64")).Render(heading+"\n\n"+body.String()),
+		bodyStyle.Render(m.status),
+	)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openResumeList lists the large uploads that did not finish yet.
+func (m uploadModel) openResumeList() (uploadModel, error) {
+	uploads, err := cli.LoadLargeUploads()
+	if err != nil {
+		return m, fmt.Errorf("failed to load unfinished uploads: %w", err)
+	}
+	m.resumable = nil
+	for _, upload := range uploads {
+		if !upload.Done {
+			m.resumable = append(m.resumable, upload)
+		}
+	}
+	m.resumeCursor = 0
+	m.status = ""
+	m.repoInput.Blur()
+	m.state = resumeUploads
+	return m, nil
+}
+
+func (m uploadModel) updateResume(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up":
+		if m.resumeCursor > 0 {
+			m.resumeCursor--
+		}
+	case "down":
+		if m.resumeCursor < len(m.resumable)-1 {
+			m.resumeCursor++
+		}
+	case "enter":
+		if len(m.resumable) > 0 {
+			return m.startLargeUpload(m.resumable[m.resumeCursor])
+		}
+	case "d": // Forget the upload; what was committed stays on the Hub
+		if len(m.resumable) == 0 {
+			return m, nil
+		}
+		if err := cli.RemoveLargeUpload(m.resumable[m.resumeCursor].ID); err != nil {
+			m.status = fmt.Sprintf("Error: %v", err)
+			return m, nil
+		}
+		updated, err := m.openResumeList()
+		if err != nil {
+			m.status = fmt.Sprintf("Error: %v", err)
+			return m, nil
+		}
+		m = updated
+	case "b":
+		m.state = inputUploadRepo
+		m.repoInput.Focus()
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// startLargeUpload runs upload, new or unfinished, in the background.
+func (m uploadModel) startLargeUpload(upload *cli.LargeUpload) (tea.Model, tea.Cmd) {
+	m = m.beginUpload()
+	m.largeUpload = upload
+	m.status = "Resuming..."
+	if len(upload.Files) == 0 {
+		m.status = "Scanning files..."
+	}
+
+	limiter, statusChan, progressChan := m.limiter, m.statusChan, m.progressChan
+	run := func() tea.Msg {
+		err := cli.RunLargeUpload(upload, limiter,
+			func(status string) {
+				statusChan <- status
+			},
+			func(progress cli.DownloadProgress) {
+				progressChan <- progress
+			})
+		var commit *cli.CommitInfo
+		if n := len(upload.Commits); n > 0 {
+			commit = &upload.Commits[n-1]
+		}
+		return uploadDoneMsg{commit: commit, err: err}
+	}
+	return m, tea.Batch(run, m.listen())
+}
+
+// largeUploadSummary describes how far a large upload got.
+func largeUploadSummary(upload *cli.LargeUpload) string {
+	summary := fmt.Sprintf("%d of %d files committed in %d commits", upload.Committed(), len(upload.Files), len(upload.Commits))
+	if len(upload.Files) == 0 {
+		summary = "Not scanned yet"
+	}
+	return summary
+}
+
+func (m uploadModel) resumeView() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ff5f00")).Padding(1).Align(lipgloss.Center)
+	bodyStyle := lipgloss.NewStyle().Padding(1, 2)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+
+	if len(m.resumable) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Top,
+			headerStyle.Render("Unfinished Uploads"),
+			bodyStyle.Render("No unfinished uploads.\n\nPress B to go back"),
+		)
+	}
+
+	var s strings.Builder
+	for i, upload := range m.resumable {
+		cursor := "  "
+		if i == m.resumeCursor {
+			cursor = "> "
+		}
+		fmt.Fprintf(&s, "%s%s → %s (%s)\n", cursor, upload.LocalPath, upload.RepoID, upload.Revision)
+		s.WriteString(dimStyle.Render(fmt.Sprintf("    %s, last run %s", largeUploadSummary(upload), upload.UpdatedAt.Format("2006-01-02 15:04"))) + "\n")
+		if upload.Error != "" {
+			s.WriteString(errorStyle.Render("    "+upload.Error) + "\n")
+		}
+	}
+	if m.status != "" {
+		s.WriteString("\n" + m.status + "\n")
+	}
+	s.WriteString("\n↑/↓ select, Enter to resume, D forget, B back")
+
+	return lipgloss.JoinVertical(lipgloss.Top,
+		headerStyle.Render("Unfinished Uploads"),
+		bodyStyle.Render(s.String()),
+	)
+}
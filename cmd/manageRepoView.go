@@ -36,8 +36,13 @@ type manageModel struct {
 	currentField   int
 	status         string
 	error          string
+	statusChan     chan string
+	listening      bool
 }
 
+type manageStatusMsg string
+type manageDoneMsg struct{ err error }
+
 var operations = []string{
 	"Create Repository",
 	"Delete Repository",
@@ -87,6 +92,49 @@ func InitialManageModel() manageModel {
 		fromRepoInput: fromRepoInput,
 		toRepoInput:   toRepoInput,
 		isPrivate:     false,
+		statusChan:    make(chan string),
+	}
+}
+
+// runOperation performs the confirmed operation in the background. Retries
+// of failing Hub calls show up in the status line meanwhile.
+func (m manageModel) runOperation() tea.Cmd {
+	op, statusChan := m.selectedOp, m.statusChan
+	token, repoType, repoName, org := m.tokenInput.Value(), m.repoTypeInput.Value(), m.repoNameInput.Value(), m.orgInput.Value()
+	isPrivate, sdk := m.isPrivate, m.sdkInput.Value()
+	fromRepo, toRepo := m.fromRepoInput.Value(), m.toRepoInput.Value()
+
+	return func() tea.Msg {
+		updateStatus := func(status string) {
+			statusChan <- status
+		}
+		var err error
+		switch op {
+		case "Create Repository":
+			err = cli.CreateRepo(token, repoType, repoName, org, isPrivate, sdk, updateStatus)
+		case "Delete Repository":
+			err = cli.DeleteRepo(token, repoType, repoName, org, updateStatus)
+		case "Update Repository Visibility":
+			err = cli.UpdateRepoVisibility(token, repoType, repoName, isPrivate, updateStatus)
+		case "Move Repository":
+			err = cli.MoveRepo(token, fromRepo, toRepo, repoType, updateStatus)
+		}
+		return manageDoneMsg{err: err}
+	}
+}
+
+// listen arms the status listener once; it re-arms itself after every message.
+func (m *manageModel) listen() tea.Cmd {
+	if m.listening {
+		return nil
+	}
+	m.listening = true
+	return listenForManageStatus(m.statusChan)
+}
+
+func listenForManageStatus(ch chan string) tea.Cmd {
+	return func() tea.Msg {
+		return manageStatusMsg(<-ch)
 	}
 }
 
@@ -98,6 +146,18 @@ func (m manageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case manageStatusMsg:
+		m.status = string(msg)
+		return m, listenForManageStatus(m.statusChan)
+
+	case manageDoneMsg:
+		if msg.err != nil {
+			m.error = msg.err.Error()
+		} else {
+			m.status = "Operation completed successfully!"
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		// Handle space key press first, before text input processing
 		if msg.String() == " " {
@@ -342,46 +402,9 @@ func (m manageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case confirmOperation:
 				m.state = processingOperation
-				go func() {
-					var err error
-					switch m.selectedOp {
-					case "Create Repository":
-						err = cli.CreateRepo(
-							m.tokenInput.Value(),
-							m.repoTypeInput.Value(),
-							m.repoNameInput.Value(),
-							m.orgInput.Value(),
-							m.isPrivate,
-							m.sdkInput.Value(),
-						)
-					case "Delete Repository":
-						err = cli.DeleteRepo(
-							m.tokenInput.Value(),
-							m.repoTypeInput.Value(),
-							m.repoNameInput.Value(),
-							m.orgInput.Value(),
-						)
-					case "Update Repository Visibility":
-						err = cli.UpdateRepoVisibility(
-							m.tokenInput.Value(),
-							m.repoTypeInput.Value(),
-							m.repoNameInput.Value(),
-							m.isPrivate,
-						)
-					case "Move Repository":
-						err = cli.MoveRepo(
-							m.tokenInput.Value(),
-							m.fromRepoInput.Value(),
-							m.toRepoInput.Value(),
-							m.repoTypeInput.Value(),
-						)
-					}
-					if err != nil {
-						m.error = err.Error()
-					} else {
-						m.status = "Operation completed successfully!"
-					}
-				}()
+				m.status = "Working..."
+				m.error = ""
+				return m, tea.Batch(m.runOperation(), m.listen())
 			}
 		}
 	}
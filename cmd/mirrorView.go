@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type mirrorPlanMsg struct {
+	plan *cli.MirrorPlan
+	err  error
+}
+
+// openMirrorForm asks for the repo, local folder and revision to mirror,
+// sharing its inputs with the verify form.
+func (m model) openMirrorForm() model {
+	m = m.openVerifyForm()
+	m.state = inputMirror
+	return m
+}
+
+func planMirror(repoType, repoID, revision, path string, statusChan chan string) tea.Cmd {
+	return func() tea.Msg {
+		plan, err := cli.PlanMirror(repoType, repoID, revision, path, func(status string) {
+			statusChan <- status
+		})
+		return mirrorPlanMsg{plan: plan, err: err}
+	}
+}
+
+// mirrorRevision is the revision typed in the form, or the default branch.
+func (m model) mirrorRevision() string {
+	if revision := m.verifyRevInput.Value(); revision != "" {
+		return revision
+	}
+	return cli.DefaultRevision
+}
+
+func (m model) updateMirror(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch m.state {
+	case inputMirror:
+		switch msg.String() {
+		case "up", "down":
+			m.verifyInput(m.verifyField).Blur()
+			if msg.String() == "up" {
+				m.verifyField = (m.verifyField + verifyFields - 1) % verifyFields
+			} else {
+				m.verifyField = (m.verifyField + 1) % verifyFields
+			}
+			m.verifyInput(m.verifyField).Focus()
+			return m, nil
+
+		case "ctrl+t":
+			m.repoType = nextRepoType(m.repoType)
+			return m, nil
+
+		case "enter": // Dry run first; nothing is written until the diff is confirmed
+			if m.repoInput.Value() == "" || m.verifyPathInput.Value() == "" {
+				return m, nil
+			}
+			m.verifyInput(m.verifyField).Blur()
+			m.state = planningMirror
+			m.mirrorPlan = nil
+			m.mirrorDelete = false
+			m.status = "Listing repository files..."
+			return m, tea.Batch(
+				planMirror(m.repoType, m.repoInput.Value(), m.mirrorRevision(), m.verifyPathInput.Value(), m.statusChan),
+				m.listen(),
+			)
+		}
+
+		input := m.verifyInput(m.verifyField)
+		*input, cmd = input.Update(msg)
+		return m, cmd
+
+	case mirrorDiff:
+		switch msg.String() {
+		case "d": // Toggle removing files the repo no longer has
+			m.mirrorDelete = !m.mirrorDelete
+			return m, nil
+
+		case "enter":
+			if m.mirrorPlan == nil {
+				return m, nil
+			}
+			return m.applyMirror()
+
+		case "b":
+			m.state = inputRepo
+			m.mirrorPlan = nil
+			m.status = ""
+			m.repoInput.Focus()
+			return m, textinput.Blink
+		}
+	}
+	return m, nil
+}
+
+// applyMirror carries out the plan: extra files are removed when asked to,
+// then new and changed files are queued as a download pinned to the commit.
+func (m model) applyMirror() (tea.Model, tea.Cmd) {
+	plan, path := m.mirrorPlan, m.verifyPathInput.Value()
+	if m.mirrorDelete && len(plan.Extra) > 0 {
+		if err := plan.RemoveExtra(path); err != nil {
+			m.status = fmt.Sprintf("Error: %v", err)
+			return m, nil
+		}
+	}
+
+	files := plan.ToFetch()
+	if len(files) == 0 {
+		m.state = inputRepo
+		m.mirrorPlan = nil
+		m.status = fmt.Sprintf("%s is in sync with %s.", path, m.repoInput.Value())
+		m.repoInput.Focus()
+		return m, textinput.Blink
+	}
+
+	revision := plan.Commit
+	if revision == "" {
+		revision = m.mirrorRevision()
+	}
+	job := cli.NewDownloadJob(m.repoType, m.repoInput.Value(), revision, files, path)
+	job.Ref = m.mirrorRevision()
+	m.mirrorPlan = nil
+	cmd := m.enqueue(job)
+	if m.state != downloading {
+		m.state = queuePanel
+		m.returnState = inputRepo
+	}
+	return m, cmd
+}
+
+func (m model) mirrorView() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#fe6375")).Padding(1).Align(lipgloss.Center)
+	bodyStyle := lipgloss.NewStyle().Padding(1)
+	addStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787"))
+	changeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8b064"))
+	removeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	switch m.state {
+	case inputMirror:
+		labels := []string{"Repo", "Local folder", "Revision"}
+		var fields string
+		for i, label := range labels {
+			cursor := " "
+			if i == m.verifyField {
+				cursor = ">"
+			}
+			fields += fmt.Sprintf("%s %s: %s\n", cursor, label, m.verifyInput(i).View())
+		}
+		fields += fmt.Sprintf("  Repo type: %s\n", m.repoType)
+		return lipgloss.JoinVertical(lipgloss.Top,
+			headerStyle.Render("Mirror Repo to Local Folder"),
+			bodyStyle.Render(fields+"\nUse ↑/↓ to move, Ctrl+T to change the repo type, Enter to compare"),
+		)
+
+	case planningMirror:
+		return lipgloss.JoinVertical(lipgloss.Top,
+			headerStyle.Render("Comparing"),
+			bodyStyle.Render(m.status),
+		)
+	}
+
+	plan := m.mirrorPlan
+	if plan == nil {
+		return lipgloss.JoinVertical(lipgloss.Top,
+			headerStyle.Render("Mirror Diff"),
+			bodyStyle.Render(removeStyle.Render(m.status)),
+			bodyStyle.Render("[B] Back"),
+		)
+	}
+
+	var lines []string
+	for _, file := range plan.Added {
+		lines = append(lines, addStyle.Render("+ "+file))
+	}
+	for _, file := range plan.Changed {
+		lines = append(lines, changeStyle.Render("~ "+file))
+	}
+	for _, file := range plan.Extra {
+		if m.mirrorDelete {
+			lines = append(lines, removeStyle.Render("- "+file))
+		} else {
+			lines = append(lines, dimStyle.Render("  "+file+"  (kept)"))
+		}
+	}
+	if len(lines) > maxVisibleTransfers {
+		hidden := len(lines) - maxVisibleTransfers
+		lines = append(lines[:maxVisibleTransfers], dimStyle.Render(fmt.Sprintf("... and %d more", hidden)))
+	}
+	if plan.InSync() {
+		lines = append(lines, addStyle.Render("Already in sync."))
+	}
+
+	summary := fmt.Sprintf("%s at %s\n%d new, %d changed, %d up to date, %d not in the repo  •  %s to fetch",
+		m.repoInput.Value(), shortSHA(plan.Commit), len(plan.Added), len(plan.Changed), len(plan.UpToDate), len(plan.Extra), cli.FormatBytes(plan.FetchBytes))
+	deletion := "[D] Delete files not in the repo: off"
+	if m.mirrorDelete {
+		deletion = removeStyle.Render(fmt.Sprintf("[D] Delete files not in the repo: on (%d files)", len(plan.Extra)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Top,
+		headerStyle.Render("Mirror Diff (dry run)"),
+		bodyStyle.Render(summary+"\n\n"+strings.Join(lines, "\n")),
+		bodyStyle.Render(deletion+"\n[ENTER] Apply  [B] Back"),
+		bodyStyle.Render(m.status),
+	)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SwitchProfileMsg asks the app to make Profile active and reload the views
+// for its account.
+type SwitchProfileMsg struct {
+	Profile cli.Profile
+}
+
+// ProfileSwitchFailedMsg tells Settings why the profile was not switched.
+type ProfileSwitchFailedMsg struct {
+	Err error
+}
+
+// BusyView is implemented by views that can run transfers in the background.
+// The app does not replace views while one of them is busy.
+type BusyView interface {
+	Busy() bool
+}
+
+// openProfiles lists the saved profiles with the active one selected.
+func (m SettingsModel) openProfiles() (SettingsModel, error) {
+	profiles, err := cli.LoadProfiles()
+	if err != nil {
+		return m, fmt.Errorf("failed to load profiles: %w", err)
+	}
+	m.profiles = profiles.Profiles
+	m.profileCursor = 0
+	for i, profile := range m.profiles {
+		if profile.Name == m.profile.Name {
+			m.profileCursor = i
+		}
+	}
+	m.state = settingsProfiles
+	return m, nil
+}
+
+func (m SettingsModel) updateProfiles(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.state {
+	case settingsProfiles:
+		switch msg.String() {
+		case "up":
+			if m.profileCursor > 0 {
+				m.profileCursor--
+			}
+		case "down":
+			if m.profileCursor < len(m.profiles)-1 {
+				m.profileCursor++
+			}
+		case "n":
+			m.state = settingsNewProfile
+			m.nameInput.SetValue("")
+			m.endpointInput.SetValue("")
+			m.nameInput.Focus()
+			m.endpointInput.Blur()
+			m.status = ""
+			return m, textinput.Blink
+		case "b":
+			m.state = settingsInfo
+			m.status = ""
+		case "enter":
+			profile := m.profiles[m.profileCursor]
+			if profile.Name == m.profile.Name {
+				m.state = settingsInfo
+				return m, nil
+			}
+			m.status = ""
+			return m, func() tea.Msg { return SwitchProfileMsg{Profile: profile} }
+		}
+		return m, nil
+
+	case settingsNewProfile:
+		switch msg.String() {
+		case "ctrl+b":
+			m.state = settingsProfiles
+			m.status = ""
+			return m, nil
+		case "up", "down":
+			m.toggleProfileField()
+			return m, nil
+		case "enter":
+			if m.nameInput.Focused() {
+				m.toggleProfileField()
+				return m, nil
+			}
+			name := strings.TrimSpace(m.nameInput.Value())
+			if err := cli.AddProfile(name, strings.TrimSpace(m.endpointInput.Value())); err != nil {
+				m.status = fmt.Sprintf("Error: %v", err)
+				return m, nil
+			}
+			updated, err := m.openProfiles()
+			if err != nil {
+				m.status = fmt.Sprintf("Error: %v", err)
+				return m, nil
+			}
+			m = updated
+			for i, profile := range m.profiles {
+				if profile.Name == name {
+					m.profileCursor = i
+				}
+			}
+			m.status = fmt.Sprintf("Added profile %s. Press Enter to switch to it.", name)
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		if m.nameInput.Focused() {
+			m.nameInput, cmd = m.nameInput.Update(msg)
+		} else {
+			m.endpointInput, cmd = m.endpointInput.Update(msg)
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *SettingsModel) toggleProfileField() {
+	if m.nameInput.Focused() {
+		m.nameInput.Blur()
+		m.endpointInput.Focus()
+	} else {
+		m.endpointInput.Blur()
+		m.nameInput.Focus()
+	}
+}
+
+func (m SettingsModel) profilesView() string {
+	styleHeader := lipgloss.NewStyle().Bold(true)
+	styleText := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
+	styleDim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	var s strings.Builder
+	if m.state == settingsNewProfile {
+		s.WriteString(styleHeader.Render("New Profile") + "\n\n")
+		s.WriteString(styleText.Render("Name: ") + m.nameInput.View() + "\n")
+		s.WriteString(styleText.Render("Endpoint: ") + m.endpointInput.View() + "\n\n")
+		if m.status != "" {
+			s.WriteString(m.status + "\n\n")
+		}
+		s.WriteString(styleDim.Render("↑/↓ switch field, Enter to save, Ctrl+B back"))
+		return s.String()
+	}
+
+	s.WriteString(styleHeader.Render("Profiles") + "\n\n")
+	for i, profile := range m.profiles {
+		cursor := "  "
+		if i == m.profileCursor {
+			cursor = "> "
+		}
+		line := fmt.Sprintf("%s%s  %s", cursor, profile.Name, styleDim.Render(profile.HubURL()))
+		if profile.Name == m.profile.Name {
+			line += styleText.Render("  (active)")
+		}
+		s.WriteString(line + "\n")
+	}
+	s.WriteString("\n")
+	if m.status != "" {
+		s.WriteString(m.status + "\n\n")
+	}
+	s.WriteString(styleDim.Render("↑/↓ select, Enter to switch, N new profile, B back"))
+	return s.String()
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// updateQueue handles keys in the download queue panel.
+func (m model) updateQueue(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	jobs := m.queue.Jobs
+	if m.queueCursor >= len(jobs) {
+		m.queueCursor = len(jobs) - 1
+	}
+	if m.queueCursor < 0 {
+		m.queueCursor = 0
+	}
+
+	switch msg.String() {
+	case "up":
+		if m.queueCursor > 0 {
+			m.queueCursor--
+		}
+
+	case "down":
+		if m.queueCursor < len(jobs)-1 {
+			m.queueCursor++
+		}
+
+	case "shift+up", "K": // Run the selected job earlier
+		m.queueCursor = m.queue.Move(m.queueCursor, -1)
+		m.saveQueue()
+
+	case "shift+down", "J": // Run the selected job later
+		m.queueCursor = m.queue.Move(m.queueCursor, 1)
+		m.saveQueue()
+
+	case "d", "delete":
+		if m.queueCursor < len(jobs) {
+			if jobs[m.queueCursor].Status == cli.JobRunning {
+				m.status = "Cancel the running job before removing it."
+				return m, nil
+			}
+			m.queue.Remove(m.queueCursor)
+			m.saveQueue()
+		}
+
+	case "r": // Run a failed or canceled job again
+		if m.queueCursor < len(jobs) {
+			job := jobs[m.queueCursor]
+			if job.Status == cli.JobFailed || job.Status == cli.JobCanceled {
+				if len(job.Failed) > 0 {
+					job.Files = job.Failed
+					job.Failed = nil
+				}
+				job.Status = cli.JobQueued
+				job.Error = ""
+				m.saveQueue()
+				return m, m.runNext()
+			}
+		}
+
+	case "g": // Start the queue if nothing is running
+		return m, m.runNext()
+
+	case "c":
+		m.queue.ClearFinished()
+		m.saveQueue()
+
+	case "enter": // Watch the running job
+		if m.queueCursor < len(jobs) && jobs[m.queueCursor].Status == cli.JobRunning {
+			m.state = downloading
+		}
+
+	case "b":
+		m.state = m.returnState
+		if m.state == queuePanel {
+			m.state = inputRepo
+		}
+	}
+	return m, nil
+}
+
+func (m model) queueView() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#fe6375")).Padding(1).Align(lipgloss.Center)
+	bodyStyle := lipgloss.NewStyle().Padding(1)
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
+	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64aef8"))
+	doneStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787"))
+	failedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	queuedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	var lines []string
+	for i, job := range m.queue.Jobs {
+		repo := job.RepoID
+		if job.RepoType == "dataset" || job.RepoType == "space" {
+			repo = job.RepoType + "s/" + repo
+		}
+		if job.Revision != "" {
+			repo += "@" + shortSHA(job.Revision)
+		}
+		line := fmt.Sprintf("%-8s %s  (%d files → %s)", job.Status, repo, len(job.Files), job.Path)
+		switch job.Status {
+		case cli.JobRunning:
+			if m.transfer.BytesTotal > 0 {
+				line += fmt.Sprintf("  %.0f%%", m.transfer.Percent()*100)
+			}
+			line = activeStyle.Render(line)
+		case cli.JobDone:
+			line = doneStyle.Render(line)
+		case cli.JobFailed:
+			line = failedStyle.Render(line + "  " + job.Error)
+		default:
+			line = queuedStyle.Render(line)
+		}
+		marker := "  "
+		if i == m.queueCursor {
+			marker = cursorStyle.Render("➤ ")
+		}
+		lines = append(lines, marker+line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, queuedStyle.Render("The queue is empty."))
+	}
+
+	help := "[↑/↓] Select  [Shift+↑/↓] Reorder  [D] Remove  [R] Retry  [G] Start  [C] Clear finished  [Enter] Watch running job  [B] Back"
+	return lipgloss.JoinVertical(lipgloss.Top,
+		headerStyle.Render(fmt.Sprintf("Download Queue (%d waiting)", m.queue.Pending())),
+		bodyStyle.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#f88e64")).Render(strings.Join(lines, "\n")+"\n\n"+help),
+		bodyStyle.Render(m.status),
+	)
+}
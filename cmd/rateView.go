@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rateEditor edits a bandwidth limit while a transfer runs: either the
+// global limit shared by all transfers or the limit of the running one.
+type rateEditor struct {
+	input   textinput.Model
+	editing bool
+	global  bool
+}
+
+func newRateEditor() rateEditor {
+	input := textinput.New()
+	input.Placeholder = "no limit"
+	return rateEditor{input: input}
+}
+
+func (e *rateEditor) open(global bool, current int64) tea.Cmd {
+	e.editing = true
+	e.global = global
+	e.input.SetValue("")
+	if current > 0 {
+		e.input.SetValue(cli.FormatBytes(current))
+	}
+	e.input.CursorEnd()
+	e.input.Focus()
+	return textinput.Blink
+}
+
+// update feeds a key to the input. Once enter is pressed the typed limit is
+// applied: the global one is also saved, the transfer one goes to setTransfer.
+func (e *rateEditor) update(msg tea.KeyMsg, setTransfer func(int64)) (tea.Cmd, error) {
+	switch msg.String() {
+	case "enter":
+		var limit int64
+		if value := strings.TrimSpace(e.input.Value()); value != "" {
+			var err error
+			if limit, err = cli.ParseBytes(strings.TrimSuffix(value, "/s")); err != nil {
+				return nil, err
+			}
+		}
+		if e.global {
+			cli.SetGlobalRateLimit(limit)
+			config, err := cli.LoadConfig()
+			if err != nil {
+				config = &cli.Config{}
+			}
+			config.MaxRate = limit
+			if err := cli.SaveConfig(config); err != nil {
+				return nil, fmt.Errorf("failed to save rate limit: %w", err)
+			}
+		} else {
+			setTransfer(limit)
+		}
+		e.editing = false
+		e.input.Blur()
+		return nil, nil
+	}
+
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return cmd, nil
+}
+
+// view renders both limits, or the input while one is being edited.
+func (e rateEditor) view(transferLimit int64) string {
+	if e.editing {
+		name := "This transfer"
+		if e.global {
+			name = "All transfers"
+		}
+		return fmt.Sprintf("%s: %s/s  (e.g. 5MB, empty for none, [ENTER] apply)", name, e.input.View())
+	}
+	return fmt.Sprintf("Limit: %s this transfer, %s all transfers  ([L] / [G] to change)",
+		formatRate(transferLimit), formatRate(cli.GlobalRateLimit()))
+}
+
+func formatRate(bytesPerSecond int64) string {
+	if bytesPerSecond <= 0 {
+		return "none"
+	}
+	return cli.FormatBytes(bytesPerSecond) + "/s"
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const maxVisibleRevisions = 12
+
+// revisionOption is one pickable branch, tag or commit.
+type revisionOption struct {
+	kind     string // "branch", "tag" or "commit"
+	name     string
+	commit   string
+	title    string
+	revision string // What is sent to the Hub
+}
+
+func revisionOptions(refs *cli.GitRefs, commits []cli.GitCommit) []revisionOption {
+	var options []revisionOption
+	for _, b := range refs.Branches {
+		option := revisionOption{kind: "branch", name: b.Name, commit: b.TargetCommit, revision: b.Name}
+		// Keep the default branch on top so Enter picks it right away
+		if b.Name == cli.DefaultRevision {
+			options = append([]revisionOption{option}, options...)
+		} else {
+			options = append(options, option)
+		}
+	}
+	for _, t := range refs.Tags {
+		options = append(options, revisionOption{kind: "tag", name: t.Name, commit: t.TargetCommit, revision: t.Name})
+	}
+	for _, c := range commits {
+		options = append(options, revisionOption{
+			kind:     "commit",
+			name:     shortSHA(c.ID),
+			commit:   c.ID,
+			title:    fmt.Sprintf("%s  %s", c.Date.Format("2006-01-02"), c.Title),
+			revision: c.ID,
+		})
+	}
+	return options
+}
+
+// shortSHA abbreviates full commit hashes; branch and tag names are kept.
+func shortSHA(sha string) string {
+	if len(sha) == 40 {
+		return sha[:7]
+	}
+	return sha
+}
+
+type repoFilesMsg struct {
+	id       int
+	revision string
+	files    []cli.RepoFile
+	sha      string
+	err      error
+}
+
+type revisionsMsg struct {
+	id      int
+	refs    *cli.GitRefs
+	commits []cli.GitCommit
+	err     error
+}
+
+// startLoading shows status while Hub calls run in the background. Answers
+// are only taken while the load with the returned model's loadID is shown.
+func (m model) startLoading(status string) model {
+	m.loadingFrom = m.state
+	m.state = loadingRepo
+	m.loadID++
+	m.status = status
+	return m
+}
+
+// loadFailed goes back to where loading started and shows err.
+func (m model) loadFailed(err error) model {
+	m.state = m.loadingFrom
+	m.status = repoErrorMessage(m.repoType, m.repoInput.Value(), err)
+	return m
+}
+
+// updateLoading only lets the user leave; the answer is then dropped.
+func (m model) updateLoading(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "b" {
+		m.state = m.loadingFrom
+		m.status = ""
+	}
+	return m, nil
+}
+
+// openRevisions lists the refs and recent commits of the repo being
+// downloaded in the background; revisionsLoaded opens the picker.
+func (m model) openRevisions() (tea.Model, tea.Cmd) {
+	m = m.startLoading("Listing revisions...")
+	return m, tea.Batch(listRevisions(m.loadID, m.repoType, m.repoInput.Value(), m.statusChan), m.listen())
+}
+
+func listRevisions(id int, repoType, repoID string, statusChan chan string) tea.Cmd {
+	return func() tea.Msg {
+		refs, commits, err := cli.RepoRevisions(repoType, repoID, func(status string) {
+			statusChan <- status
+		})
+		return revisionsMsg{id: id, refs: refs, commits: commits, err: err}
+	}
+}
+
+func (m model) revisionsLoaded(msg revisionsMsg) (tea.Model, tea.Cmd) {
+	if m.state != loadingRepo || msg.id != m.loadID {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m.loadFailed(msg.err), nil
+	}
+	m.revisions = revisionOptions(msg.refs, msg.commits)
+	m.revisionCursor = 0
+	m.revisionInput.SetValue("")
+	m.revisionInput.Focus()
+	m.repoInput.Blur()
+	m.state = selectRevision
+	m.status = "Ready to download."
+	return m, nil
+}
+
+func (m model) updateRevision(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "up":
+		if m.revisionCursor > 0 {
+			m.revisionCursor--
+		}
+		return m, nil
+
+	case "down":
+		if m.revisionCursor < len(m.revisions)-1 {
+			m.revisionCursor++
+		}
+		return m, nil
+
+	case "enter":
+		// A typed revision wins over the highlighted one
+		revision := strings.TrimSpace(m.revisionInput.Value())
+		if revision == "" && m.revisionCursor < len(m.revisions) {
+			revision = m.revisions[m.revisionCursor].revision
+		}
+		if revision == "" {
+			revision = cli.DefaultRevision
+		}
+
+		m = m.startLoading(fmt.Sprintf("Listing files at %s...", revision))
+		return m, m.listFiles(revision)
+
+	// Plain B would be typed into the revision input, as in the gate form
+	case "ctrl+b":
+		m.state = inputRepo
+		m.status = ""
+		m.revisionInput.Blur()
+		m.repoInput.Focus()
+		return m, textinput.Blink
+	}
+
+	m.revisionInput, cmd = m.revisionInput.Update(msg)
+	return m, cmd
+}
+
+// listFiles lists the files of the repo at revision in the background as
+// part of the current load. The listing carries the last commit of every
+// file, which the Hub computes on the fly, so it can take a while.
+func (m *model) listFiles(revision string) tea.Cmd {
+	id, repoType, repoID, statusChan := m.loadID, m.repoType, m.repoInput.Value(), m.statusChan
+	return tea.Batch(func() tea.Msg {
+		files, sha, err := cli.ListRepoFiles(repoType, repoID, revision, func(status string) {
+			statusChan <- status
+		})
+		return repoFilesMsg{id: id, revision: revision, files: files, sha: sha, err: err}
+	}, m.listen())
+}
+
+// filesLoaded checks the gate of a freshly listed revision. Listings made
+// after access was granted go straight to the file list.
+func (m model) filesLoaded(msg repoFilesMsg) (tea.Model, tea.Cmd) {
+	if m.state != loadingRepo || msg.id != m.loadID {
+		return m, nil
+	}
+	if msg.err != nil {
+		return m.loadFailed(msg.err), nil
+	}
+	m.revision = msg.revision
+	m.commitSHA = msg.sha
+	m.setFiles(msg.files)
+	m.revisionInput.Blur()
+	if m.loadingFrom == gatedAccess {
+		m.state = selectFiles
+		m.status = gateStatusMessage(cli.AccessGranted)
+		return m, nil
+	}
+	m.status = "Checking access..."
+	return m, m.checkGate()
+}
+
+func (m model) revisionView() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#fe6375")).Padding(1).Align(lipgloss.Center)
+	bodyStyle := lipgloss.NewStyle().Padding(1)
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
+	kindStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64aef8"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	start := 0
+	if m.revisionCursor >= maxVisibleRevisions {
+		start = m.revisionCursor - maxVisibleRevisions + 1
+	}
+	end := start + maxVisibleRevisions
+	if end > len(m.revisions) {
+		end = len(m.revisions)
+	}
+
+	var lines []string
+	for i := start; i < end; i++ {
+		r := m.revisions[i]
+		marker := "  "
+		if i == m.revisionCursor {
+			marker = cursorStyle.Render("➤ ")
+		}
+		line := fmt.Sprintf("%s %s", kindStyle.Render(fmt.Sprintf("%-6s", r.kind)), r.name)
+		if r.kind == "commit" {
+			line += "  " + dimStyle.Render(r.title)
+		} else {
+			line += "  " + dimStyle.Render(shortSHA(r.commit))
+		}
+		lines = append(lines, marker+line)
+	}
+	if hidden := len(m.revisions) - (end - start); hidden > 0 {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  ... %d more", hidden)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Top,
+		headerStyle.Render("Select Revision"),
+		bodyStyle.BorderStyle(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#f88e64")).Render(strings.Join(lines, "\n")+"\n\n[↑/↓] Move  [ENTER] Confirm  [Ctrl+B] Back"),
+		bodyStyle.Render(fmt.Sprintf("Or type a branch, tag or commit SHA:\n\n%s", m.revisionInput.View())),
+		bodyStyle.Render(m.status),
+	)
+}
@@ -3,12 +3,24 @@ package cmd
 import (
 	"Lazyface/internal/cli"
 	"fmt"
+	"os"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+type settingsState int
+
+const (
+	settingsInfo settingsState = iota
+	settingsProfiles
+	settingsNewProfile
+)
+
 type SettingsModel struct {
+	state         settingsState
+	loggedIn      bool
 	username      string
 	fullName      string
 	organizations []string
@@ -17,24 +29,47 @@ type SettingsModel struct {
 	logoutBtn     bool
 	cursor        int
 	pageSize      int
+	profile       cli.Profile
+	profiles      []cli.Profile
+	profileCursor int
+	nameInput     textinput.Model
+	endpointInput textinput.Model
+	status        string
 }
 
+// InitialSettingsModel shows the account of the active profile, or only
+// the profile settings when nobody is logged in on it.
 func InitialSettingsModel() (SettingsModel, error) {
-	userData, err := cli.LoadUserData()
-	if err != nil {
-		return SettingsModel{}, fmt.Errorf("failed to load user data: %w", err)
-	}
+	nameInput := textinput.New()
+	nameInput.Placeholder = "Profile name"
+
+	endpointInput := textinput.New()
+	endpointInput.Placeholder = "https://hub.example.com"
 
-	return SettingsModel{
-		username:      userData.Name,
-		fullName:      userData.FullName,
-		organizations: userData.Orgs,
-		tokenName:     userData.TokenName,
-		permissions:   userData.Permissions,
+	m := SettingsModel{
 		logoutBtn:     false,
 		cursor:        0,
 		pageSize:      10,
-	}, nil
+		profile:       cli.ActiveProfile(),
+		nameInput:     nameInput,
+		endpointInput: endpointInput,
+	}
+
+	userData, err := cli.LoadUserData()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return m, fmt.Errorf("failed to load user data: %w", err)
+	}
+
+	m.loggedIn = true
+	m.username = userData.Name
+	m.fullName = userData.FullName
+	m.organizations = userData.Orgs
+	m.tokenName = userData.TokenName
+	m.permissions = userData.Permissions
+	return m, nil
 }
 
 func (m SettingsModel) Init() tea.Cmd {
@@ -43,11 +78,29 @@ func (m SettingsModel) Init() tea.Cmd {
 
 func (m SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case ProfileSwitchFailedMsg:
+		m.status = fmt.Sprintf("Error: %v", msg.Err)
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.state != settingsInfo {
+			return m.updateProfiles(msg)
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case "p":
+			updated, err := m.openProfiles()
+			if err != nil {
+				m.status = fmt.Sprintf("Error: %v", err)
+				return m, nil
+			}
+			return updated, nil
 		case "f":
+			if !m.loggedIn {
+				return m, nil
+			}
 			fmt.Println("Logging out...")
 			if err := cli.Logout(); err != nil {
 				fmt.Println("Error logging out:", err)
@@ -67,12 +120,31 @@ func (m SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m SettingsModel) View() string {
+	if m.state != settingsInfo {
+		return m.profilesView()
+	}
+
 	styleHeader := lipgloss.NewStyle().Bold(true)
 	styleText := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
 	permText := lipgloss.NewStyle().Foreground(lipgloss.Color("#fe6375"))
 	btnText := lipgloss.NewStyle().Background(lipgloss.Color("#fe6375"))
 	styleDim := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
+	profileInfo := "\n" + styleHeader.Width(50).Render("Profile") + "\n" +
+		styleText.Render("Name: ") + m.profile.Name + "\n" +
+		styleText.Render("Endpoint: ") + m.profile.HubURL() + "\n" +
+		styleDim.Render("Press p to manage profiles") + "\n"
+	if m.status != "" {
+		profileInfo += m.status + "\n"
+	}
+
+	if !m.loggedIn {
+		return lipgloss.JoinVertical(lipgloss.Left,
+			profileInfo,
+			styleDim.Render("Not logged in on this profile. Use the Auth view to log in."),
+		)
+	}
+
 	userInfo := "\n" + styleHeader.Width(50).Render("User Information") + "\n" +
 		styleText.Render("Username: ") + m.username + "\n" +
 		styleText.Render("Full Name: ") + m.fullName + "\n" +
@@ -101,6 +173,7 @@ func (m SettingsModel) View() string {
 	pagination := styleDim.Render(fmt.Sprintf("Page %d/%d (Use ↑/↓ to navigate through permissions list)", currentPage, totalPages))
 
 	return lipgloss.JoinVertical(lipgloss.Left,
+		profileInfo,
 		first,
 		permissionsHeader,
 		permissionsList,
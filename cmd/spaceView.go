@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checkSpace measures free space at the chosen destination against the
+// selected files before the confirmation screen is shown. Once the sync plan
+// is known only the files still to fetch count.
+func (m *model) checkSpace() {
+	_, size := m.selectionSize()
+	if m.syncPlan != nil {
+		size = m.syncPlan.FetchBytes
+	}
+	m.space, m.spaceErr = cli.CheckSpace(m.path, size)
+}
+
+// openBudget starts editing the per-job download budget.
+func (m model) openBudget() (tea.Model, tea.Cmd) {
+	m.editingBudget = true
+	m.budgetInput.SetValue("")
+	if m.space.Budget > 0 {
+		m.budgetInput.SetValue(cli.FormatBytes(m.space.Budget))
+	}
+	m.budgetInput.CursorEnd()
+	m.budgetInput.Focus()
+	return m, textinput.Blink
+}
+
+func (m model) updateBudget(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg.String() == "enter" {
+		var budget int64
+		if value := strings.TrimSpace(m.budgetInput.Value()); value != "" {
+			var err error
+			if budget, err = cli.ParseBytes(value); err != nil {
+				m.status = fmt.Sprintf("Error: %v", err)
+				return m, nil
+			}
+		}
+
+		config, err := cli.LoadConfig()
+		if err != nil {
+			config = &cli.Config{}
+		}
+		config.MaxDownloadBytes = budget
+		if err := cli.SaveConfig(config); err != nil {
+			m.status = fmt.Sprintf("Error saving budget: %v", err)
+			return m, nil
+		}
+		m.editingBudget = false
+		m.budgetInput.Blur()
+		m.status = ""
+		m.checkSpace()
+		return m, nil
+	}
+
+	m.budgetInput, cmd = m.budgetInput.Update(msg)
+	return m, cmd
+}
+
+// spaceSummary renders free space and budget for the confirmation screen,
+// highlighting anything that blocks the download.
+func (m model) spaceSummary() string {
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f8b064"))
+	badStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+
+	var lines []string
+	free := fmt.Sprintf("Free space: %s", cli.FormatBytes(int64(m.space.Free)))
+	switch {
+	case m.spaceErr != nil:
+		lines = append(lines, warnStyle.Render(fmt.Sprintf("Could not check free space: %v", m.spaceErr)))
+	case !m.space.Fits():
+		lines = append(lines, badStyle.Render(free+"  (not enough space)"))
+	case m.space.LowSpace():
+		lines = append(lines, warnStyle.Render(free+"  (less than 1 GB will be left)"))
+	default:
+		lines = append(lines, okStyle.Render(free))
+	}
+
+	budget := "Budget per job: none"
+	if m.space.Budget > 0 {
+		budget = fmt.Sprintf("Budget per job: %s", cli.FormatBytes(m.space.Budget))
+	}
+	if m.space.OverBudget() {
+		budget = badStyle.Render(budget + "  (exceeded)")
+	}
+	if m.editingBudget {
+		budget = "Budget per job: " + m.budgetInput.View() + "  (e.g. 50GB, empty for none, [ENTER] save)"
+	} else {
+		budget += "  ([B] to change)"
+	}
+	lines = append(lines, budget)
+	return strings.Join(lines, "\n")
+}
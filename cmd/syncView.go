@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type syncPlanMsg struct {
+	path string
+	plan cli.SyncPlan
+}
+
+// planSync compares the selection with what the destination already holds.
+// Hashing existing files can take a while, so it runs in the background.
+func (m *model) planSync() tea.Cmd {
+	m.syncPlan = nil
+	var files []cli.RepoFile
+	for i, f := range m.repoFiles {
+		if m.checked[i] {
+			files = append(files, f)
+		}
+	}
+	path, cache := m.path, m.downloadPathChoice == "HF Cache"
+	return func() tea.Msg {
+		return syncPlanMsg{path: path, plan: cli.PlanSync(path, files, cache)}
+	}
+}
+
+// syncSummary renders how much of the selection is already present.
+func (m model) syncSummary() string {
+	if m.syncPlan == nil {
+		return "Checking local files..."
+	}
+	return fmt.Sprintf("%d up to date, %d to fetch (%s)", len(m.syncPlan.UpToDate), len(m.syncPlan.ToFetch), cli.FormatBytes(m.syncPlan.FetchBytes))
+}
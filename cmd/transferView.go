@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// updateDownloading handles keys while a download runs or after it ended.
+func (m model) updateDownloading(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// The order changes as files progress, so the cursor follows a file
+	order := transferOrder(m.transfer)
+	cursor := transferIndex(order, m.transferSelected)
+
+	switch msg.String() {
+	case "up":
+		if cursor > 0 {
+			m.transferSelected = order[cursor-1].Name
+		}
+
+	case "down":
+		if cursor < len(order)-1 {
+			m.transferSelected = order[cursor+1].Name
+		}
+
+	case "p": // Pause or resume the whole batch
+		if !m.downloadDone {
+			if m.control.Paused() {
+				m.control.Resume()
+				m.status = "Resuming downloads..."
+			} else {
+				m.control.Pause()
+				m.status = "Paused. Press P to resume."
+			}
+		}
+
+	case "s": // Skip the file under the cursor
+		if !m.downloadDone && cursor < len(order) {
+			f := order[cursor]
+			if f.State == cli.FileActive || f.State == cli.FileQueued {
+				m.control.Skip(f.Name)
+			}
+		}
+
+	case "c", "x": // Cancel everything, X also discards partial files
+		if !m.downloadDone {
+			m.discardPartials = msg.String() == "x"
+			m.control.Resume()
+			m.cancelDownload()
+			m.status = "Canceling..."
+		}
+
+	case "r": // Re-fetch files that failed or did not verify
+		if m.downloadDone && m.activeJob != nil && len(m.activeJob.Failed) > 0 {
+			job := cli.NewDownloadJob(m.activeJob.RepoType, m.activeJob.RepoID, m.activeJob.Revision, m.activeJob.Failed, m.activeJob.Path)
+			job.Ref, job.Cache = m.activeJob.Ref, m.activeJob.Cache
+			m.activeJob.Failed = nil
+			return m, m.enqueue(job)
+		}
+
+	case "l", "g": // Limit the bandwidth of this download, or of all transfers
+		if !m.downloadDone {
+			if msg.String() == "g" {
+				return m, m.rate.open(true, cli.GlobalRateLimit())
+			}
+			return m, m.rate.open(false, m.control.RateLimit())
+		}
+
+	case "n": // Pick another repo while this one keeps downloading
+		m.state = inputRepo
+		m.repoInput.SetValue("")
+		m.repoInput.Focus()
+		m.checked = make(map[int]bool)
+		m.selectedFiles = nil
+		return m, textinput.Blink
+	}
+	return m, nil
+}
+
+// rateLine shows the bandwidth limits below the progress bar while a
+// download runs.
+func (m model) rateLine() string {
+	if m.downloadDone || m.control == nil {
+		return ""
+	}
+	return "\n" + m.rate.view(m.control.RateLimit())
+}
+
+func (m model) transferHelp() string {
+	if m.downloadDone {
+		return "[N] New download  [Ctrl+O] Queue"
+	}
+	pause := "[P] Pause"
+	if m.control != nil && m.control.Paused() {
+		pause = "[P] Resume"
+	}
+	return pause + "  [S] Skip file  [C] Cancel  [X] Cancel & discard partials  [↑/↓] Select file\n[N] New download  [Ctrl+O] Queue"
+}
+
+// transferOrder lists files with active transfers first, followed by
+// queued, failed and finished ones.
+func transferOrder(p cli.DownloadProgress) []cli.FileProgress {
+	states := []cli.FileState{cli.FileActive, cli.FileVerifying, cli.FileHashing, cli.FileQueued, cli.FileFailed, cli.FileSkipped, cli.FileDone, cli.FileUpToDate}
+	order := make([]cli.FileProgress, 0, len(p.Files))
+	for _, state := range states {
+		for _, f := range p.Files {
+			if f.State == state {
+				order = append(order, f)
+			}
+		}
+	}
+	return order
+}
+
+// transferIndex returns where the file named selected is in order, or the
+// first position when it is not listed.
+func transferIndex(order []cli.FileProgress, selected string) int {
+	for i, f := range order {
+		if f.Name == selected {
+			return i
+		}
+	}
+	return 0
+}
+
+// transferSummary renders overall bytes, throughput and ETA.
+func transferSummary(p cli.DownloadProgress) string {
+	summary := fmt.Sprintf("%s / %s", cli.FormatBytes(p.BytesDone), cli.FormatBytes(p.BytesTotal))
+	if p.Rate > 0 {
+		summary += fmt.Sprintf("  •  %s/s", cli.FormatBytes(int64(p.Rate)))
+	}
+	if p.ETA > 0 {
+		summary += fmt.Sprintf("  •  ETA %s", cli.FormatDuration(p.ETA))
+	}
+	return summary
+}
+
+// renderTransfers lists the files of a transfer in transferOrder, showing
+// at most maxVisibleTransfers lines around the cursor. arrow marks files
+// being transferred, "↓" for downloads and "↑" for uploads.
+func renderTransfers(p cli.DownloadProgress, cursor int, arrow string) string {
+	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64aef8"))
+	doneStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787"))
+	failedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	queuedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
+
+	order := transferOrder(p)
+	lines := make([]string, len(order))
+	for i, f := range order {
+		var line string
+		switch f.State {
+		case cli.FileActive:
+			line = fmt.Sprintf("%s %s  %s / %s (%.0f%%)", arrow, f.Name, cli.FormatBytes(f.BytesDone), cli.FormatBytes(f.BytesTotal), f.Percent()*100)
+			if f.ResumedFrom > 0 {
+				line += fmt.Sprintf("  resuming from %s", cli.FormatBytes(f.ResumedFrom))
+			}
+			line = activeStyle.Render(line)
+		case cli.FileVerifying:
+			line = activeStyle.Render(fmt.Sprintf("⟳ %s  verifying checksum", f.Name))
+		case cli.FileHashing:
+			line = activeStyle.Render(fmt.Sprintf("# %s  hashing %s", f.Name, cli.FormatBytes(f.BytesTotal)))
+		case cli.FileQueued:
+			line = queuedStyle.Render(fmt.Sprintf("• %s  %s", f.Name, cli.FormatBytes(f.BytesTotal)))
+		case cli.FileFailed:
+			line = failedStyle.Render(fmt.Sprintf("✗ %s  %v", f.Name, f.Err))
+		case cli.FileSkipped:
+			line = queuedStyle.Render(fmt.Sprintf("- %s  skipped", f.Name))
+		case cli.FileDone:
+			line = doneStyle.Render(fmt.Sprintf("✓ %s  %s", f.Name, cli.FormatBytes(f.BytesTotal)))
+		case cli.FileUpToDate:
+			line = queuedStyle.Render(fmt.Sprintf("= %s  up to date", f.Name))
+		}
+		marker := "  "
+		if i == cursor {
+			marker = cursorStyle.Render("➤ ")
+		}
+		lines[i] = marker + line
+	}
+
+	start := 0
+	if cursor >= maxVisibleTransfers {
+		start = cursor - maxVisibleTransfers + 1
+	}
+	end := start + maxVisibleTransfers
+	if end > len(lines) {
+		end = len(lines)
+	}
+	visible := lines[start:end]
+	if hidden := len(lines) - len(visible); hidden > 0 {
+		visible = append(visible, queuedStyle.Render(fmt.Sprintf("  ... and %d more", hidden)))
+	}
+
+	header := fmt.Sprintf("%d active, %d queued, %d done, %d up to date, %d failed, %d skipped",
+		p.Count(cli.FileActive), p.Count(cli.FileQueued), p.Count(cli.FileDone), p.Count(cli.FileUpToDate), p.Count(cli.FileFailed), p.Count(cli.FileSkipped))
+	return header + "\n" + strings.Join(visible, "\n")
+}
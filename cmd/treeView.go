@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	maxVisibleTree = 15 //Rows of the file tree shown at once
+	treePageSize   = 10 //Rows skipped by PgUp/PgDown
+)
+
+// treeNode is a folder or file of the repo being browsed.
+type treeNode struct {
+	name     string
+	file     int // Index into model.files, -1 for folders
+	size     int64
+	depth    int
+	expanded bool
+	parent   *treeNode
+	children []*treeNode
+}
+
+func (n *treeNode) isDir() bool {
+	return n.file < 0
+}
+
+// buildTree arranges files into folders, listing folders before files and
+// both alphabetically. Top-level folders start expanded.
+func buildTree(files []cli.RepoFile) *treeNode {
+	root := &treeNode{file: -1, depth: -1, expanded: true}
+	dirs := map[string]*treeNode{"": root}
+
+	var dirFor func(path string) *treeNode
+	dirFor = func(path string) *treeNode {
+		if dir, ok := dirs[path]; ok {
+			return dir
+		}
+		parentPath, name := "", path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			parentPath, name = path[:i], path[i+1:]
+		}
+		parent := dirFor(parentPath)
+		dir := &treeNode{name: name, file: -1, depth: parent.depth + 1, parent: parent, expanded: parent == root}
+		parent.children = append(parent.children, dir)
+		dirs[path] = dir
+		return dir
+	}
+
+	for i, f := range files {
+		dirPath, name := "", f.Path
+		if j := strings.LastIndex(f.Path, "/"); j >= 0 {
+			dirPath, name = f.Path[:j], f.Path[j+1:]
+		}
+		dir := dirFor(dirPath)
+		dir.children = append(dir.children, &treeNode{name: name, file: i, size: f.Size, depth: dir.depth + 1, parent: dir})
+		for d := dir; d != nil; d = d.parent {
+			d.size += f.Size
+		}
+	}
+
+	var sortChildren func(n *treeNode)
+	sortChildren = func(n *treeNode) {
+		sort.SliceStable(n.children, func(i, j int) bool {
+			a, b := n.children[i], n.children[j]
+			if a.isDir() != b.isDir() {
+				return a.isDir()
+			}
+			return a.name < b.name
+		})
+		for _, c := range n.children {
+			sortChildren(c)
+		}
+	}
+	sortChildren(root)
+	return root
+}
+
+// visibleRows flattens the expanded part of the tree in display order.
+func (n *treeNode) visibleRows() []*treeNode {
+	var rows []*treeNode
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		for _, c := range n.children {
+			rows = append(rows, c)
+			if c.isDir() && c.expanded {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return rows
+}
+
+// fileIndexes returns the indexes of every file at or below n.
+func (n *treeNode) fileIndexes() []int {
+	if !n.isDir() {
+		return []int{n.file}
+	}
+	var indexes []int
+	for _, c := range n.children {
+		indexes = append(indexes, c.fileIndexes()...)
+	}
+	return indexes
+}
+
+// selection counts how many of the files at or below n are checked.
+func (n *treeNode) selection(checked map[int]bool) (selected, total int) {
+	for _, i := range n.fileIndexes() {
+		if checked[i] {
+			selected++
+		}
+		total++
+	}
+	return selected, total
+}
+
+// setFiles replaces the browsed files and rebuilds the tree.
+func (m *model) setFiles(files []cli.RepoFile) {
+	m.repoFiles = files
+	m.files = make([]string, len(files))
+	for i, f := range files {
+		m.files[i] = f.Path
+	}
+	m.tree = buildTree(files)
+	m.treeCursor = 0
+	m.checked = make(map[int]bool)
+}
+
+// updateTree handles the keys of the file tree and reports whether it used
+// msg, so other keys reach the view's general ones.
+func (m model) updateTree(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	if msg.String() == "b" { // Back to the revision picker
+		m.state = selectRevision
+		m.revisionInput.Focus()
+		return m, textinput.Blink, true
+	}
+
+	rows := m.tree.visibleRows()
+	if len(rows) == 0 {
+		return m, nil, false
+	}
+	if m.treeCursor >= len(rows) {
+		m.treeCursor = len(rows) - 1
+	}
+	node := rows[m.treeCursor]
+
+	switch msg.String() {
+	case "up", "k":
+		if m.treeCursor > 0 {
+			m.treeCursor--
+		}
+
+	case "down", "j":
+		if m.treeCursor < len(rows)-1 {
+			m.treeCursor++
+		}
+
+	case "pgup":
+		m.treeCursor = max(m.treeCursor-treePageSize, 0)
+
+	case "pgdown":
+		m.treeCursor = min(m.treeCursor+treePageSize, len(rows)-1)
+
+	case "home", "g":
+		m.treeCursor = 0
+
+	case "end", "G":
+		m.treeCursor = len(rows) - 1
+
+	case "right", "l": // Expand a folder, or step into an expanded one
+		if node.isDir() {
+			if !node.expanded {
+				node.expanded = true
+			} else if len(node.children) > 0 {
+				m.treeCursor++
+			}
+		}
+
+	case "left", "h": // Collapse a folder, or jump to the parent folder
+		if node.isDir() && node.expanded {
+			node.expanded = false
+		} else if node.parent != m.tree {
+			for i, row := range rows {
+				if row == node.parent {
+					m.treeCursor = i
+					break
+				}
+			}
+		}
+
+	case " ": // Toggle a file, or everything under a folder
+		selected, total := node.selection(m.checked)
+		for _, i := range node.fileIndexes() {
+			if selected == total {
+				delete(m.checked, i)
+			} else {
+				m.checked[i] = true
+			}
+		}
+
+	case "a": //Toggle select all
+		allSelected := len(m.checked) == len(m.files)
+		m.checked = make(map[int]bool)
+		if !allSelected {
+			for i := range m.files {
+				m.checked[i] = true
+			}
+		}
+
+	case "/":
+		updated, cmd := m.openFilter()
+		return updated, cmd, true
+
+	case "enter":
+		selectedFiles := []string{}
+		for i, file := range m.files {
+			if m.checked[i] {
+				selectedFiles = append(selectedFiles, file)
+			}
+		}
+		m.selectedFiles = selectedFiles
+		if len(selectedFiles) > 0 {
+			m.state = selectDownloadPath
+		}
+
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// renderTree draws the rows of the tree around the cursor.
+func (m model) renderTree() string {
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f88e64"))
+	dirStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#64aef8")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	lfsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#a496ff"))
+
+	rows := m.tree.visibleRows()
+	if len(rows) == 0 {
+		return dimStyle.Render("This revision has no files.")
+	}
+	if m.treeCursor >= len(rows) {
+		m.treeCursor = len(rows) - 1
+	}
+
+	start := 0
+	if m.treeCursor >= maxVisibleTree {
+		start = m.treeCursor - maxVisibleTree + 1
+	}
+	end := min(start+maxVisibleTree, len(rows))
+
+	var lines []string
+	if start > 0 {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  ↑ %d more", start)))
+	}
+	for i := start; i < end; i++ {
+		node := rows[i]
+		marker := "  "
+		if i == m.treeCursor {
+			marker = cursorStyle.Render("➤ ")
+		}
+
+		selected, total := node.selection(m.checked)
+		checkmark := "[ ]"
+		if selected == total {
+			checkmark = "[✓]"
+		} else if selected > 0 {
+			checkmark = "[~]"
+		}
+
+		indent := strings.Repeat("  ", node.depth)
+		name := node.name
+		if node.isDir() {
+			arrow := "▸"
+			if node.expanded {
+				arrow = "▾"
+			}
+			name = dirStyle.Render(arrow + " " + name + "/")
+		} else if m.repoFiles[node.file].IsLFS() {
+			name += " " + lfsStyle.Render("LFS")
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s%s  %s", marker, checkmark, indent, name, dimStyle.Render(cli.FormatBytes(node.size))))
+	}
+	if end < len(rows) {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("  ↓ %d more", len(rows)-end)))
+	}
+	lines = append(lines, "", dimStyle.Render(m.nodeDetails(rows[m.treeCursor])))
+	return strings.Join(lines, "\n")
+}
+
+// nodeDetails describes the row under the cursor: storage, hash and last
+// commit for a file, file count and size for a folder.
+func (m model) nodeDetails(node *treeNode) string {
+	if node.isDir() {
+		return fmt.Sprintf("%d files, %s", len(node.fileIndexes()), cli.FormatBytes(node.size))
+	}
+
+	f := m.repoFiles[node.file]
+	details := fmt.Sprintf("%s  •  %d bytes", f.Path, f.Size)
+	if f.IsLFS() {
+		details += fmt.Sprintf("  •  LFS sha256 %s", shortHash(f.LFS.OID))
+	} else {
+		details += fmt.Sprintf("  •  git sha1 %s", shortHash(f.OID))
+	}
+	if c := f.LastCommit; c != nil {
+		details += fmt.Sprintf("\nLast commit %s  %s  %s", shortSHA(c.ID), c.Date.Format("2006-01-02"), c.Title)
+	}
+	return details
+}
+
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
+
+// selectionSize returns how many files are checked and their total size.
+func (m model) selectionSize() (int, int64) {
+	var count int
+	var size int64
+	for i, f := range m.repoFiles {
+		if m.checked[i] {
+			count++
+			size += f.Size
+		}
+	}
+	return count, size
+}
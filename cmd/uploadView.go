@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"Lazyface/internal/cli"
+	"errors"
 	"fmt"
 
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -11,6 +13,15 @@ import (
 
 type uploadState int
 
+type uploadStatusMsg string
+
+type uploadProgressMsg cli.DownloadProgress
+
+type uploadDoneMsg struct {
+	commit *cli.CommitInfo
+	err    error
+}
+
 const (
 	inputUploadRepo uploadState = iota
 	inputLocalPath
@@ -18,6 +29,7 @@ const (
 	advancedOptions
 	uploadConfirmation
 	uploading
+	resumeUploads
 )
 
 type uploadModel struct {
@@ -36,6 +48,20 @@ type uploadModel struct {
 	advancedFields  []string
 	advancedInputs  map[string]*textinput.Model
 	showingAdvanced bool
+	limiter         *cli.RateLimiter // Bandwidth of the running upload
+	rate            rateEditor
+	finished        bool
+	failed          bool
+	commit          *cli.CommitInfo // Created by the last upload
+	statusChan      chan string
+	progressChan    chan cli.DownloadProgress
+	listening       bool
+	progress        progress.Model
+	transfer        cli.DownloadProgress
+	large           bool             // Upload in several resumable commits
+	largeUpload     *cli.LargeUpload // The running or last large upload
+	resumable       []*cli.LargeUpload
+	resumeCursor    int
 }
 
 func InitialUploadModel() uploadModel {
@@ -99,6 +125,10 @@ func InitialUploadModel() uploadModel {
 		status:         "Ready to upload.",
 		advancedFields: advancedFields,
 		advancedInputs: advancedInputs,
+		rate:           newRateEditor(),
+		statusChan:     make(chan string),
+		progressChan:   make(chan cli.DownloadProgress),
+		progress:       progress.New(progress.WithScaledGradient("#fd5392", "#f86f64"), progress.WithWidth(80)),
 	}
 }
 
@@ -110,11 +140,81 @@ func (m uploadModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case uploadStatusMsg:
+		if !m.finished {
+			m.status = string(msg)
+		}
+		return m, listenForUploadStatus(m.statusChan)
+
+	case uploadProgressMsg:
+		m.transfer = cli.DownloadProgress(msg)
+		return m, listenForUploadProgress(m.progressChan)
+
+	case uploadDoneMsg:
+		m.finished = true
+		m.failed = msg.err != nil
+		if msg.err != nil {
+			m.status = uploadErrorMessage(m.repoInput.Value(), msg.err)
+			return m, nil
+		}
+		m.commit = msg.commit
+		m.status = "Upload complete!"
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.state == resumeUploads {
+			return m.updateResume(msg)
+		}
+		if m.state == uploading && !m.finished && m.rate.editing {
+			cmd, err := m.rate.update(msg, m.limiter.SetLimit)
+			if err != nil {
+				m.status = fmt.Sprintf("Error: %v", err)
+			}
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 
+		case "ctrl+r": // Continue a large upload that did not finish
+			if m.state == inputUploadRepo {
+				updated, err := m.openResumeList()
+				if err != nil {
+					m.status = fmt.Sprintf("Error: %v", err)
+					return m, nil
+				}
+				return updated, nil
+			}
+
+		case "m": // Switch between a single commit and a large, resumable upload
+			if m.state == uploadConfirmation {
+				m.large = !m.large
+				return m, nil
+			}
+
+		case "r":
+			if m.state == uploading && m.finished && m.failed && m.largeUpload != nil {
+				return m.startLargeUpload(m.largeUpload)
+			}
+
+		case "n":
+			if m.state == uploading && m.finished {
+				m.state = inputUploadRepo
+				m.status = "Ready to upload."
+				m.largeUpload = nil
+				m.repoInput.Focus()
+				return m, textinput.Blink
+			}
+
+		case "l", "g": // Limit the bandwidth of this upload, or of all transfers
+			if m.state == uploading && !m.finished {
+				if msg.String() == "g" {
+					return m, m.rate.open(true, cli.GlobalRateLimit())
+				}
+				return m, m.rate.open(false, m.limiter.Limit())
+			}
+
 		case "enter":
 			switch m.state {
 			case inputUploadRepo:
@@ -151,25 +251,22 @@ func (m uploadModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.state = uploadConfirmation
 				}
 			case uploadConfirmation:
-				m.state = uploading
-				go func() {
-					_, err := cli.UploadToHuggingFace(
+				if m.large {
+					return m.startLargeUpload(cli.NewLargeUpload(
 						m.repoInput.Value(),
 						m.localPathInput.Value(),
 						m.repoPathInput.Value(),
 						m.repoTypeInput.Value(),
 						m.includeInput.Value(),
 						m.excludeInput.Value(),
-						m.deleteInput.Value(),
 						m.commitMsgInput.Value(),
 						m.revisionInput.Value(),
-					)
-					if err != nil {
-						m.status = fmt.Sprintf("Error: %v", err)
-					} else {
-						m.status = "Upload complete!"
-					}
-				}()
+					))
+				}
+				m = m.beginUpload()
+				m.largeUpload = nil
+				m.status = "Collecting files..."
+				return m, tea.Batch(m.runUpload(), m.listen())
 			}
 
 		case "tab":
@@ -218,6 +315,99 @@ func (m uploadModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// Busy reports whether an upload is running.
+func (m uploadModel) Busy() bool {
+	return m.state == uploading && !m.finished
+}
+
+// beginUpload switches to the uploading state with fresh progress and a
+// limiter for the new upload.
+func (m uploadModel) beginUpload() uploadModel {
+	m.state = uploading
+	m.limiter = cli.NewRateLimiter(0)
+	m.finished = false
+	m.failed = false
+	m.commit = nil
+	m.transfer = cli.DownloadProgress{}
+	return m
+}
+
+func listenForUploadStatus(ch chan string) tea.Cmd {
+	return func() tea.Msg {
+		return uploadStatusMsg(<-ch)
+	}
+}
+
+func listenForUploadProgress(ch chan cli.DownloadProgress) tea.Cmd {
+	return func() tea.Msg {
+		return uploadProgressMsg(<-ch)
+	}
+}
+
+// listen arms the status and progress listeners once; each re-arms itself
+// after delivering a message.
+func (m *uploadModel) listen() tea.Cmd {
+	if m.listening {
+		return nil
+	}
+	m.listening = true
+	return tea.Batch(
+		listenForUploadStatus(m.statusChan),
+		listenForUploadProgress(m.progressChan),
+	)
+}
+
+// runUpload uploads with the values of the form in the background and
+// streams its status and progress back into the model.
+func (m uploadModel) runUpload() tea.Cmd {
+	repoID, localPath, pathInRepo := m.repoInput.Value(), m.localPathInput.Value(), m.repoPathInput.Value()
+	repoType, include, exclude := m.repoTypeInput.Value(), m.includeInput.Value(), m.excludeInput.Value()
+	deletePattern, message, revision := m.deleteInput.Value(), m.commitMsgInput.Value(), m.revisionInput.Value()
+	limiter, statusChan, progressChan := m.limiter, m.statusChan, m.progressChan
+	return func() tea.Msg {
+		commit, err := cli.UploadToHuggingFace(repoID, localPath, pathInRepo, repoType, include, exclude, deletePattern, message, revision, limiter,
+			func(status string) {
+				statusChan <- status
+			},
+			func(progress cli.DownloadProgress) {
+				progressChan <- progress
+			})
+		return uploadDoneMsg{commit: commit, err: err}
+	}
+}
+
+// uploadErrorMessage explains a failed upload: the step and file it failed
+// at, and what to do about the Hub's answer.
+func uploadErrorMessage(repoID string, err error) string {
+	message := fmt.Sprintf("Error: %v", err)
+	var uploadErr *cli.UploadError
+	if errors.As(err, &uploadErr) {
+		message = fmt.Sprintf("Failed while %s", uploadErr.Step)
+		if uploadErr.File != "" {
+			message += " " + uploadErr.File
+		}
+		message += fmt.Sprintf(":\n%v", uploadErr.Err)
+	}
+
+	var hint string
+	switch {
+	case errors.Is(err, cli.ErrRepoNotFound):
+		hint = fmt.Sprintf("%s was not found. Check the name and repo type, or create it in the Manage tab.", repoID)
+	case errors.Is(err, cli.ErrRevisionNotFound):
+		hint = fmt.Sprintf("That branch does not exist in %s.", repoID)
+	case errors.Is(err, cli.ErrLoginRequired), errors.Is(err, cli.ErrAccessDenied):
+		hint = fmt.Sprintf("Your token cannot write to %s. Check it has write access.", repoID)
+	case errors.Is(err, cli.ErrRateLimited):
+		hint = "The Hub is rate limiting requests and retries did not get through. Wait a few minutes and try again."
+	case errors.Is(err, cli.ErrHubUnavailable):
+		hint = "The Hub is unavailable right now and retries did not get through. Try again later."
+	}
+	if hint != "" {
+		message += "\n\n" + hint
+	}
+	return message
+}
+
 func (m uploadModel) View() string {
 	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#ff5f00")).Padding(1).Align(lipgloss.Center)
 	bodyStyle := lipgloss.NewStyle().Padding(1, 2)
@@ -229,7 +419,7 @@ func (m uploadModel) View() string {
 			bodyStyle.Render(fmt.Sprintf("%s\n\n%s\n\n%s",
 				"Enter Repository Name:",
 				m.repoInput.View(),
-				"Press Enter to confirm, Q to quit")),
+				"Press Enter to confirm, Ctrl+R to resume an unfinished upload, Q to quit")),
 		)
 
 	case inputLocalPath:
@@ -271,18 +461,61 @@ func (m uploadModel) View() string {
 		)
 
 	case uploadConfirmation:
+		mode := "Single commit"
+		if m.large {
+			mode = "Large folder, resumable commits of up to 100 files"
+			if m.deleteInput.Value() != "" {
+				mode += " (delete pattern is ignored)"
+			}
+		}
 		return lipgloss.JoinVertical(lipgloss.Top,
 			headerStyle.Render("Confirmation"),
-			bodyStyle.Render(fmt.Sprintf("Upload Details:\nRepository: %s\nLocal Path: %s\nRepo Path: %s\n\nPress Enter to start upload, Q to quit",
+			bodyStyle.Render(fmt.Sprintf("Upload Details:\nRepository: %s\nLocal Path: %s\nRepo Path: %s\nMode: %s\n\nPress Enter to start upload, M to switch mode, Q to quit",
 				m.repoInput.Value(),
 				m.localPathInput.Value(),
-				m.repoPathInput.Value())),
+				m.repoPathInput.Value(),
+				mode)),
 		)
 
+	case resumeUploads:
+		return m.resumeView()
+
 	case uploading:
+		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+		files := bodyStyle.Render(renderTransfers(m.transfer, -1, "↑"))
+		if m.finished {
+			// The large upload is no longer written to once finished
+			var large string
+			if m.largeUpload != nil {
+				large = largeUploadSummary(m.largeUpload) + "\n\n"
+			}
+			help := "\n\nPress N for a new upload"
+			if m.failed && m.largeUpload != nil {
+				help = "\n\nPress R to resume where it stopped, N for a new upload"
+			}
+
+			if m.failed {
+				return lipgloss.JoinVertical(lipgloss.Top,
+					headerStyle.Render("Upload failed"),
+					bodyStyle.Render(large+errorStyle.Render(m.status)+help),
+					files,
+				)
+			}
+			details := "Nothing was committed"
+			if m.commit != nil {
+				details = fmt.Sprintf("Commit: %s\nURL: %s", m.commit.SHA, m.commit.URL)
+			}
+			return lipgloss.JoinVertical(lipgloss.Top,
+				headerStyle.Render("Upload complete"),
+				bodyStyle.Render(large+details+help),
+				files,
+			)
+		}
 		return lipgloss.JoinVertical(lipgloss.Top,
 			headerStyle.Render("Uploading..."),
 			bodyStyle.Render(m.status),
+			bodyStyle.Render(m.progress.ViewAs(m.transfer.Percent())+"\n"+transferSummary(m.transfer)+"\n"+m.rate.view(m.limiter.Limit())),
+			files,
 		)
 	}
 
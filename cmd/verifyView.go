@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"Lazyface/internal/cli"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type verifyDoneMsg struct {
+	results []cli.VerifyResult
+	err     error
+}
+
+const verifyFields = 3 // Repo, local folder, revision
+
+func (m model) openVerifyForm() model {
+	m.state = inputVerify
+	m.verifyField = 1
+	m.repoInput.Blur()
+	m.verifyPathInput.Focus()
+	return m
+}
+
+func runVerify(repoType, repoID, revision, path string, statusChan chan string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := cli.VerifyLocal(repoType, repoID, revision, path, func(status string) {
+			statusChan <- status
+		})
+		return verifyDoneMsg{results: results, err: err}
+	}
+}
+
+// verifyInput returns the text input behind form field i.
+func (m *model) verifyInput(i int) *textinput.Model {
+	switch i {
+	case 0:
+		return &m.repoInput
+	case 1:
+		return &m.verifyPathInput
+	default:
+		return &m.verifyRevInput
+	}
+}
+
+func (m model) updateVerify(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch m.state {
+	case inputVerify:
+		switch msg.String() {
+		case "up", "down":
+			m.verifyInput(m.verifyField).Blur()
+			if msg.String() == "up" {
+				m.verifyField = (m.verifyField + verifyFields - 1) % verifyFields
+			} else {
+				m.verifyField = (m.verifyField + 1) % verifyFields
+			}
+			m.verifyInput(m.verifyField).Focus()
+			return m, nil
+
+		case "ctrl+t":
+			m.repoType = nextRepoType(m.repoType)
+			return m, nil
+
+		case "enter":
+			if m.repoInput.Value() == "" || m.verifyPathInput.Value() == "" {
+				return m, nil
+			}
+			revision := m.verifyRevInput.Value()
+			if revision == "" {
+				revision = cli.DefaultRevision
+			}
+			m.verifyInput(m.verifyField).Blur()
+			m.state = verifying
+			m.status = "Listing repository files..."
+			return m, tea.Batch(
+				runVerify(m.repoType, m.repoInput.Value(), revision, m.verifyPathInput.Value(), m.statusChan),
+				m.listen(),
+			)
+		}
+
+		input := m.verifyInput(m.verifyField)
+		*input, cmd = input.Update(msg)
+		return m, cmd
+
+	case verifyResults:
+		switch msg.String() {
+		case "r": // Re-fetch everything that did not verify
+			var files []string
+			for _, r := range m.verifyResults {
+				if r.Status != cli.VerifyOK {
+					files = append(files, r.File)
+				}
+			}
+			if len(files) == 0 {
+				return m, nil
+			}
+			revision := m.verifyRevInput.Value()
+			if revision == "" {
+				revision = cli.DefaultRevision
+			}
+			job := cli.NewDownloadJob(m.repoType, m.repoInput.Value(), revision, files, m.verifyPathInput.Value())
+			cmd := m.enqueue(job)
+			if m.state != downloading {
+				m.state = queuePanel
+				m.returnState = inputRepo
+			}
+			return m, cmd
+
+		case "b":
+			m.state = inputRepo
+			m.repoInput.Focus()
+			return m, textinput.Blink
+		}
+	}
+	return m, nil
+}
+
+func (m model) verifyView() string {
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#fe6375")).Padding(1).Align(lipgloss.Center)
+	bodyStyle := lipgloss.NewStyle().Padding(1)
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#5fd787"))
+	badStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	switch m.state {
+	case inputVerify:
+		labels := []string{"Repo", "Local folder", "Revision"}
+		var fields string
+		for i, label := range labels {
+			cursor := " "
+			if i == m.verifyField {
+				cursor = ">"
+			}
+			fields += fmt.Sprintf("%s %s: %s\n", cursor, label, m.verifyInput(i).View())
+		}
+		fields += fmt.Sprintf("  Repo type: %s\n", m.repoType)
+		return lipgloss.JoinVertical(lipgloss.Top,
+			headerStyle.Render("Verify Local Folder"),
+			bodyStyle.Render(fields+"\nUse ↑/↓ to move, Ctrl+T to change the repo type, Enter to verify"),
+		)
+
+	case verifying:
+		return lipgloss.JoinVertical(lipgloss.Top,
+			headerStyle.Render("Verifying"),
+			bodyStyle.Render(m.status),
+		)
+	}
+
+	counts := map[cli.VerifyStatus]int{}
+	var lines []string
+	for _, r := range m.verifyResults {
+		counts[r.Status]++
+		switch r.Status {
+		case cli.VerifyMismatch:
+			lines = append(lines, badStyle.Render("✗ "+r.File+"  checksum mismatch"))
+		case cli.VerifyMissing:
+			lines = append(lines, badStyle.Render("? "+r.File+"  missing"))
+		}
+	}
+	if len(lines) > maxVisibleTransfers {
+		hidden := len(lines) - maxVisibleTransfers
+		lines = append(lines[:maxVisibleTransfers], dimStyle.Render(fmt.Sprintf("... and %d more", hidden)))
+	}
+
+	summary := okStyle.Render(fmt.Sprintf("%d ok", counts[cli.VerifyOK])) +
+		fmt.Sprintf(", %d mismatched, %d missing", counts[cli.VerifyMismatch], counts[cli.VerifyMissing])
+	if m.status != "" {
+		summary = badStyle.Render(m.status)
+	}
+	help := "[B] Back"
+	if counts[cli.VerifyMismatch]+counts[cli.VerifyMissing] > 0 {
+		help = "[R] Re-fetch bad files  " + help
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Top,
+		headerStyle.Render("Verification Results"),
+		bodyStyle.Render(summary+"\n\n"+strings.Join(lines, "\n")),
+		bodyStyle.Render(help),
+	)
+}
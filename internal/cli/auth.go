@@ -1,15 +1,20 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
-	"os/user"
+	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
+// TokenData holds the stored tokens keyed by username. ActiveToken names the
+// entry in Tokens that is currently in use.
 type TokenData struct {
 	ActiveToken string            `json:"active_token"`
 	Tokens      map[string]string `json:"tokens"`
@@ -23,19 +28,11 @@ type UserData struct {
 }
 
 func getUserFilePath() (string, error) {
-	usr, err := user.Current()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(usr.HomeDir, ".lazyface", "user.json"), nil
+	return profileFilePath("user.json")
 }
 
 func getTokenFilePath() (string, error) {
-	usr, err := user.Current()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(usr.HomeDir, ".lazyface", "tokens.json"), nil
+	return profileFilePath("tokens.json")
 }
 
 func LoadTokens() (*TokenData, error) {
@@ -56,10 +53,27 @@ func LoadTokens() (*TokenData, error) {
 	if err := json.Unmarshal(data, &tokens); err != nil {
 		return nil, err
 	}
+	if tokens.Tokens == nil {
+		tokens.Tokens = make(map[string]string)
+	}
 
 	return &tokens, nil
 }
 
+// Active returns the token currently in use, or "" when nobody is logged in.
+func (t *TokenData) Active() string {
+	if token, ok := t.Tokens[t.ActiveToken]; ok {
+		return token
+	}
+	// Older token files stored the token's display name in ActiveToken
+	if len(t.Tokens) == 1 {
+		for _, token := range t.Tokens {
+			return token
+		}
+	}
+	return ""
+}
+
 // Save Tokens writes token data to disk
 func SaveTokens(tokens *TokenData) error {
 	path, err := getTokenFilePath()
@@ -80,86 +94,84 @@ func SaveTokens(tokens *TokenData) error {
 	return os.WriteFile(path, data, 0644)
 }
 
-func FetchAndStoreUserData(token string) error {
-	url := "https://huggingface.co/api/whoami-v2"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
+// WhoAmIResponse is the subset of /api/whoami-v2 that Lazyface uses.
+type WhoAmIResponse struct {
+	Name     string `json:"name"`
+	FullName string `json:"fullname"`
+	Orgs     []struct {
+		Name string `json:"name"`
+	} `json:"orgs"`
+	Auth struct {
+		AccessToken struct {
+			DisplayName string `json:"displayName"`
+			Role        string `json:"role"`
+			FineGrained struct {
+				Scoped []struct {
+					Permissions []string `json:"permissions"`
+				} `json:"scoped"`
+			} `json:"fineGrained"`
+		} `json:"accessToken"`
+	} `json:"auth"`
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+// WhoAmI returns the account the client's token belongs to.
+func (c *Client) WhoAmI(ctx context.Context) (*WhoAmIResponse, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("not logged in")
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch user data: %s", resp.Status)
+	var resp WhoAmIResponse
+	if err := c.getJSON(ctx, "/api/whoami-v2", &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch user data: %w", err)
 	}
+	return &resp, nil
+}
 
-	var rawResponse map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&rawResponse); err != nil {
-		return err
-	}
+func FetchAndStoreUserData(token string) error {
+	_, err := fetchAndStoreUserData(context.Background(), NewClient(token))
+	return err
+}
 
-	// Extract auth and accessToken
-	authData, ok := rawResponse["auth"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("failed to parse auth data")
-	}
-	accessToken, ok := authData["accessToken"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("failed to parse accessToken")
+func fetchAndStoreUserData(ctx context.Context, client *Client) (*WhoAmIResponse, error) {
+	whoami, err := client.WhoAmI(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract displayName from accessToken
-	displayName, ok := accessToken["displayName"].(string)
-	if !ok {
-		return fmt.Errorf("failed to parse displayName")
+	orgs := make([]string, 0, len(whoami.Orgs))
+	for _, org := range whoami.Orgs {
+		orgs = append(orgs, org.Name)
 	}
 
-	// Extract permissions directly from fineGrained.scoped
+	// Flatten the fine-grained scopes into a single permissions list
 	var permissions []string
-	if fineGrained, ok := accessToken["fineGrained"].(map[string]interface{}); ok {
-		if scoped, ok := fineGrained["scoped"].([]interface{}); ok {
-			for _, scope := range scoped {
-				if scopeMap, ok := scope.(map[string]interface{}); ok {
-					if perms, ok := scopeMap["permissions"].([]interface{}); ok {
-						for _, perm := range perms {
-							if permStr, ok := perm.(string); ok {
-								permissions = append(permissions, permStr)
-							}
-						}
-					}
-				}
-			}
-		}
+	for _, scope := range whoami.Auth.AccessToken.FineGrained.Scoped {
+		permissions = append(permissions, scope.Permissions...)
+	}
+	if len(permissions) == 0 && whoami.Auth.AccessToken.Role != "" {
+		permissions = append(permissions, whoami.Auth.AccessToken.Role)
 	}
 
-	// Map extracted data
-	userData := map[string]interface{}{
-		"name":        rawResponse["name"],
-		"fullname":    rawResponse["fullname"],
-		"orgs":        rawResponse["orgs"],
-		"tokenname":   displayName, // Save the token name
-		"permissions": permissions,
+	userData := UserData{
+		Name:        whoami.Name,
+		FullName:    whoami.FullName,
+		Orgs:        orgs,
+		TokenName:   whoami.Auth.AccessToken.DisplayName,
+		Permissions: permissions,
 	}
 
 	// Save to file
 	path, err := getUserFilePath()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
+		return nil, err
 	}
 	data, err := json.MarshalIndent(userData, "", "  ")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return os.WriteFile(path, data, 0644)
+	return whoami, os.WriteFile(path, data, 0644)
 }
 
 func LoadUserData() (*UserData, error) {
@@ -181,48 +193,44 @@ func LoadUserData() (*UserData, error) {
 	return &userData, nil
 }
 
+// WhoAmI returns the username of the active stored token.
 func WhoAmI() (string, error) {
-	return RunCommand("whoami")
+	whoami, err := DefaultClient().WhoAmI(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return whoami.Name, nil
 }
 
-func Login(token string, addGitCredential bool) error {
+// Login validates token with the Hub and stores it as the active token.
+// Retries of transient failures are reported to updateStatus.
+func Login(token string, addGitCredential bool, updateStatus func(string)) error {
 	if token == "" {
 		return fmt.Errorf("token cannot be empty")
 	}
 
-	args := []string{"login", "--token", token}
-	if addGitCredential {
-		args = append(args, "--add-to-git-credential")
-	}
-
-	output, err := RunCommand(args...)
+	client := NewClient(token)
+	whoami, err := fetchAndStoreUserData(WithRetryStatus(context.Background(), updateStatus), client)
 	if err != nil {
+		var hubErr *HubError
+		if errors.As(err, &hubErr) && hubErr.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("invalid token: %w", err)
+		}
 		return err
 	}
 
-	var activeToken string
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "The current active token is") {
-			parts := strings.Split(line, ": ")
-			if len(parts) > 1 {
-				activeToken = strings.Trim(parts[1], "`")
-			}
+	if addGitCredential {
+		if err := storeGitCredential(client.Endpoint, token); err != nil {
+			return err
 		}
 	}
 
-	if activeToken == "" {
-		return fmt.Errorf("could not retrieve active token")
-	}
-
-	err = FetchAndStoreUserData(token)
-	if err != nil {
-		return fmt.Errorf("login Successful but failed tp fetch user details: %w", err)
-	}
-	//Fetch username
-	username, err := WhoAmI()
-	if err != nil {
-		return err
+	// Keep huggingface_hub in sync so Python tooling sees the same login.
+	// Other profiles are for other Hubs, whose tokens it would not know.
+	if ActiveProfile().Name == DefaultProfile {
+		if err := writeHFToken(token); err != nil {
+			return fmt.Errorf("failed to save token: %w", err)
+		}
 	}
 
 	//Load and update token data
@@ -231,8 +239,8 @@ func Login(token string, addGitCredential bool) error {
 		return err
 	}
 
-	tokens.Tokens[username] = token
-	tokens.ActiveToken = activeToken
+	tokens.Tokens[whoami.Name] = token
+	tokens.ActiveToken = whoami.Name
 
 	//Save tokens
 	if err := SaveTokens(tokens); err != nil {
@@ -245,9 +253,10 @@ func Login(token string, addGitCredential bool) error {
 }
 
 func Logout() error {
-	_, err := RunCommand("logout")
-	if err != nil {
-		return fmt.Errorf("failed to logout: %w", err)
+	if ActiveProfile().Name == DefaultProfile {
+		if err := removeHFToken(); err != nil {
+			return fmt.Errorf("failed to logout: %w", err)
+		}
 	}
 
 	lazyfaceDir, err := getUserFilePath()
@@ -258,14 +267,71 @@ func Logout() error {
 	if err := os.RemoveAll(lazyfaceDir); err != nil {
 		return fmt.Errorf("failed to delete user data: %w", err)
 	}
-	// tokenFilePath, err := getTokenFilePath()
-	// if err != nil {
-	// 	return fmt.Errorf("failed to get token file path: %w", err)
-	// }
-	//
-	// if err := os.Remove(tokenFilePath); err != nil && !os.IsNotExist(err) {
-	// 	return fmt.Errorf("failed to delete token file: %w", err)
-	// }
-	//
+
+	// Forget the token too, or DefaultClient would keep sending it
+	tokens, err := LoadTokens()
+	if err != nil {
+		return err
+	}
+	active := tokens.Active()
+	for name, token := range tokens.Tokens {
+		if name == tokens.ActiveToken || (active != "" && token == active) {
+			delete(tokens.Tokens, name)
+		}
+	}
+	tokens.ActiveToken = ""
+	return SaveTokens(tokens)
+}
+
+// hfTokenPath mirrors huggingface_hub's token location.
+func hfTokenPath() (string, error) {
+	if path := os.Getenv("HF_TOKEN_PATH"); path != "" {
+		return path, nil
+	}
+	if home := os.Getenv("HF_HOME"); home != "" {
+		return filepath.Join(home, "token"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".cache", "huggingface", "token"), nil
+}
+
+func writeHFToken(token string) error {
+	path, err := hfTokenPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(token), 0600)
+}
+
+func removeHFToken() error {
+	path, err := hfTokenPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// storeGitCredential hands the token to git's credential helper, the same
+// way `huggingface-cli login --add-to-git-credential` does.
+func storeGitCredential(endpoint, token string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+
+	cmd := exec.Command("git", "credential", "approve")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\nusername=hf_user\npassword=%s\n\n", u.Scheme, u.Host, token))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to add git credential: %v\nOutput: %s", err, string(output))
+	}
 	return nil
 }
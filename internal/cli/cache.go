@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HubCacheDir returns the huggingface_hub cache directory, honouring
+// HF_HUB_CACHE and HF_HOME like the Python library does.
+func HubCacheDir() (string, error) {
+	if dir := os.Getenv("HF_HUB_CACHE"); dir != "" {
+		return dir, nil
+	}
+	if home := os.Getenv("HF_HOME"); home != "" {
+		return filepath.Join(home, "hub"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".cache", "huggingface", "hub"), nil
+}
+
+// RepoCacheDir returns the cache folder of a repo, e.g.
+// ~/.cache/huggingface/hub/models--org--name.
+func RepoCacheDir(repoType, repoID string) (string, error) {
+	cacheDir, err := HubCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, repoFolderName(repoType, repoID)), nil
+}
+
+func repoFolderName(repoType, repoID string) string {
+	return repoTypePath(repoType) + "--" + strings.ReplaceAll(repoID, "/", "--")
+}
+
+// blobPath is where the content with etag is stored, relative to the repo's
+// cache folder. Files with equal content share one blob.
+func blobPath(etag string) string {
+	return "blobs/" + etag
+}
+
+// linkSnapshot exposes a blob at its repo path inside the snapshot of
+// commit. The link is relative so the cache can be moved as a whole.
+// Where symlinks are not available the blob is copied instead.
+func linkSnapshot(repoDir, commit, file, etag string) error {
+	link := filepath.Join(repoDir, "snapshots", commit, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	blob := filepath.Join(repoDir, filepath.FromSlash(blobPath(etag)))
+	target, err := filepath.Rel(filepath.Dir(link), blob)
+	if err != nil {
+		return err
+	}
+	if existing, err := os.Readlink(link); err == nil && existing == target {
+		return nil
+	}
+	os.Remove(link)
+	if err := os.Symlink(target, link); err == nil {
+		return nil
+	}
+	return copyFile(blob, link)
+}
+
+// writeRef records which commit a branch or tag pointed to, so libraries
+// resolving the ref offline find the snapshot.
+func writeRef(repoDir, ref, commit string) error {
+	path := filepath.Join(repoDir, "refs", filepath.FromSlash(ref))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write ref: %w", err)
+	}
+	return os.WriteFile(path, []byte(commit), 0644)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// isCommitSHA reports whether revision is a full commit hash rather than a
+// branch or tag name.
+func isCommitSHA(revision string) bool {
+	if len(revision) != 40 {
+		return false
+	}
+	for _, c := range revision {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -2,20 +2,322 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os/exec"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
-func RunCommand(args ...string) (string, error) {
-	cmd := exec.Command("huggingface-cli", args...)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &out
+const (
+	// DefaultEndpoint is the Hub instance used when neither the profile nor
+	// HF_ENDPOINT names another.
+	DefaultEndpoint = "https://huggingface.co"
 
-	err := cmd.Run()
+	defaultUserAgent  = "lazyface/0.1"
+	defaultAPITimeout = 30 * time.Second
+)
+
+// sharedHTTPClient is reused by every Client so connections are pooled across
+// API calls and file transfers. It deliberately has no overall Timeout, since
+// that would abort large downloads; API calls are bounded by Client.Timeout.
+// Proxy and CA settings are applied to it by ConfigureNetwork.
+var sharedHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+}
+
+// Client talks to the Hugging Face Hub HTTP API.
+type Client struct {
+	Endpoint   string
+	Token      string
+	UserAgent  string
+	Timeout    time.Duration // Per API call; file transfers are not bounded by it
+	HTTPClient *http.Client
+	Retry      RetryPolicy
+}
+
+// Reasons the Hub refuses access to a repo. A *HubError matches the one
+// that applies to it with errors.Is.
+var (
+	ErrRepoNotFound     = errors.New("repository not found")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrLoginRequired    = errors.New("repository is private or does not exist, log in to access it")
+	ErrGatedRepo        = errors.New("repository is gated, access must be requested")
+	ErrAccessDenied     = errors.New("your token cannot access this repository")
+	ErrRateLimited      = errors.New("too many requests to the Hub")
+	ErrHubUnavailable   = errors.New("the Hub is temporarily unavailable")
+)
+
+// HubError is returned when the Hub answers with a non-2xx status.
+type HubError struct {
+	StatusCode int
+	Code       string // Value of the X-Error-Code header, if any
+	Message    string
+	URL        string
+	Anonymous  bool // The request carried no token
+}
+
+func (e *HubError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	}
+	return fmt.Sprintf("%d %s (%s)", e.StatusCode, http.StatusText(e.StatusCode), e.URL)
+}
+
+// Is classifies the error. The Hub answers 401 for private repos when no
+// token is sent, so an anonymous "not found" may also mean "log in".
+func (e *HubError) Is(target error) bool {
+	switch target {
+	case ErrGatedRepo:
+		return e.Code == "GatedRepo"
+	case ErrRevisionNotFound:
+		return e.Code == "RevisionNotFound"
+	case ErrLoginRequired:
+		return e.Anonymous && e.StatusCode == http.StatusUnauthorized && e.Code != "GatedRepo"
+	case ErrRepoNotFound:
+		return !e.Anonymous && (e.Code == "RepoNotFound" || (e.StatusCode == http.StatusNotFound && e.Code == ""))
+	case ErrAccessDenied:
+		return !e.Anonymous && e.Code != "GatedRepo" && e.Code != "RepoNotFound" &&
+			(e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden)
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrHubUnavailable:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// NewClient returns a client for the active profile's endpoint authenticated with token.
+// An empty token makes anonymous requests.
+func NewClient(token string) *Client {
+	return &Client{
+		Endpoint:   Endpoint(),
+		Token:      token,
+		UserAgent:  defaultUserAgent,
+		Timeout:    defaultAPITimeout,
+		HTTPClient: sharedHTTPClient,
+		Retry:      DefaultRetryPolicy,
+	}
+}
+
+// DefaultClient returns a client authenticated with the active stored token,
+// falling back to anonymous access when nobody is logged in.
+func DefaultClient() *Client {
+	tokens, err := LoadTokens()
+	if err != nil {
+		return NewClient("")
+	}
+	return NewClient(tokens.Active())
+}
+
+// url joins path onto the client's endpoint. path must already be escaped.
+func (c *Client) url(path string) string {
+	return strings.TrimRight(c.Endpoint, "/") + path
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	target := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		target = c.url(path)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
 	if err != nil {
-		return "", fmt.Errorf("command failed: %s, error: %v", out.String(), err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
 	}
+	return req, nil
+}
 
-	return out.String(), nil
+// do sends req, retrying transient failures according to c.Retry, and
+// converts non-2xx responses into a *HubError. On success the caller owns
+// the response body.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.send(c.HTTPClient, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, newHubError(resp)
+	}
+	return resp, nil
+}
+
+func newHubError(resp *http.Response) *HubError {
+	hubErr := &HubError{
+		StatusCode: resp.StatusCode,
+		Code:       resp.Header.Get("X-Error-Code"),
+		Message:    resp.Header.Get("X-Error-Message"),
+		URL:        resp.Request.URL.String(),
+		Anonymous:  resp.Request.Header.Get("Authorization") == "",
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if hubErr.Message == "" {
+		var payload struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &payload) == nil && payload.Error != "" {
+			hubErr.Message = payload.Error
+		} else {
+			hubErr.Message = strings.TrimSpace(string(body))
+		}
+	}
+	return hubErr
+}
+
+// apiContext bounds a single API call by the client's timeout.
+func (c *Client) apiContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// getJSON fetches path and decodes the JSON response into v.
+func (c *Client) getJSON(ctx context.Context, path string, v interface{}) error {
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nil
+}
+
+// getPage is getJSON for paginated endpoints. It returns the URL of the
+// next page from the Link header, or "" on the last page.
+func (c *Client) getPage(ctx context.Context, path string, v interface{}) (string, error) {
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nextPageURL(resp.Header.Get("Link")), nil
+}
+
+// nextPageURL extracts the rel="next" target from a Link header.
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(part, ";")
+		if len(segments) < 2 {
+			continue
+		}
+		for _, param := range segments[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(segments[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// sendJSON sends payload as a JSON body and decodes the response into v,
+// which may be nil when the response body is not needed.
+func (c *Client) sendJSON(ctx context.Context, method, path string, payload, v interface{}) error {
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	req, err := c.newRequest(ctx, method, path, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if v == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nil
+}
+
+// repoTypePath returns the plural URL segment used by the API for repoType.
+func repoTypePath(repoType string) string {
+	switch repoType {
+	case "dataset":
+		return "datasets"
+	case "space":
+		return "spaces"
+	default:
+		return "models"
+	}
+}
+
+// repoURLPrefix returns the non-API URL prefix of a repo, e.g. "/datasets/org/name".
+// Models live at the root of the endpoint.
+func repoURLPrefix(repoType, repoID string) string {
+	switch repoType {
+	case "dataset", "space":
+		return "/" + repoTypePath(repoType) + "/" + repoID
+	default:
+		return "/" + repoID
+	}
+}
+
+// RepoURL returns the web page of a repo on the active profile's endpoint.
+func RepoURL(repoType, repoID string) string {
+	return Endpoint() + repoURLPrefix(repoType, repoID)
+}
+
+// escapePath escapes every segment of a slash-separated repo path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
 }
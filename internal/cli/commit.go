@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+const (
+	preuploadBatchSize = 256
+	sampleSize         = 512
+)
+
+// commitFile is a local file that will be written to pathInRepo.
+type commitFile struct {
+	localPath  string
+	pathInRepo string
+	size       int64
+	sha256     string // Only computed for LFS files
+	uploadMode string // "regular" or "lfs", decided by the Hub
+	ignore     bool
+}
+
+type commitResponse struct {
+	CommitURL string `json:"commitUrl"`
+	CommitOID string `json:"commitOid"`
+}
+
+// createCommit uploads files and removes deletions in a single commit on revision.
+// File content is sent no faster than limiter and the global rate limit allow,
+// and every step is reported to tracker.
+func (c *Client) createCommit(ctx context.Context, repoType, repoID, revision, message string, files []*commitFile, deletions []string, limiter *RateLimiter, tracker *uploadTracker) (*commitResponse, error) {
+	for _, f := range files {
+		info, err := os.Stat(f.localPath)
+		if err != nil {
+			return nil, &UploadError{Step: "preparing", File: f.pathInRepo, Err: err}
+		}
+		f.size = info.Size()
+	}
+	tracker.track(files)
+
+	tracker.status(fmt.Sprintf("Preparing %d files...", len(files)))
+	if err := c.preupload(ctx, repoType, repoID, revision, files); err != nil {
+		return nil, &UploadError{Step: "preparing", Err: err}
+	}
+	for _, f := range files {
+		if f.ignore {
+			tracker.setState(f, FileSkipped)
+		}
+	}
+	if err := c.uploadLFSFiles(ctx, repoType, repoID, revision, files, limiter, tracker); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	writeLine := func(key string, value interface{}) error {
+		return enc.Encode(map[string]interface{}{"key": key, "value": value})
+	}
+
+	if err := writeLine("header", map[string]string{"summary": message, "description": ""}); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.ignore {
+			continue
+		}
+		if f.uploadMode == "lfs" {
+			err := writeLine("lfsFile", map[string]interface{}{
+				"path": f.pathInRepo,
+				"algo": "sha256",
+				"oid":  f.sha256,
+				"size": f.size,
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		content, err := os.ReadFile(f.localPath)
+		if err != nil {
+			tracker.fail(f, err)
+			return nil, &UploadError{Step: "committing", File: f.pathInRepo, Err: err}
+		}
+		tracker.setState(f, FileActive)
+		err = writeLine("file", map[string]string{
+			"path":     f.pathInRepo,
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString(content),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range deletions {
+		if err := writeLine("deletedFile", map[string]string{"path": path}); err != nil {
+			return nil, err
+		}
+	}
+
+	tracker.status("Creating commit...")
+	path := fmt.Sprintf("/api/%s/%s/commit/%s", repoTypePath(repoType), repoID, url.PathEscape(revision))
+	payload := body.Bytes()
+	open := func() (io.ReadCloser, error) {
+		return io.NopCloser(&progressReader{throttle(ctx, bytes.NewReader(payload), limiter), tracker.counter(nil)}), nil
+	}
+	reqBody, _ := open()
+	req, err := c.newRequest(ctx, http.MethodPost, path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(payload))
+	req.GetBody = open
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, &UploadError{Step: "committing", Err: err}
+	}
+	defer resp.Body.Close()
+
+	var commit commitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&commit); err != nil {
+		return nil, &UploadError{Step: "committing", Err: fmt.Errorf("failed to parse JSON: %w", err)}
+	}
+	for _, f := range files {
+		if !f.ignore && f.uploadMode != "lfs" {
+			tracker.setState(f, FileDone)
+		}
+	}
+	return &commit, nil
+}
+
+// preupload asks the Hub which files must go through LFS.
+func (c *Client) preupload(ctx context.Context, repoType, repoID, revision string, files []*commitFile) error {
+	type preuploadFile struct {
+		Path   string `json:"path"`
+		Sample string `json:"sample"`
+		Size   int64  `json:"size"`
+	}
+	var result struct {
+		Files []struct {
+			Path         string `json:"path"`
+			UploadMode   string `json:"uploadMode"`
+			ShouldIgnore bool   `json:"shouldIgnore"`
+		} `json:"files"`
+	}
+
+	path := fmt.Sprintf("/api/%s/%s/preupload/%s", repoTypePath(repoType), repoID, url.PathEscape(revision))
+	for start := 0; start < len(files); start += preuploadBatchSize {
+		end := start + preuploadBatchSize
+		if end > len(files) {
+			end = len(files)
+		}
+		batch := files[start:end]
+
+		payload := make([]preuploadFile, len(batch))
+		byPath := make(map[string]*commitFile, len(batch))
+		for i, f := range batch {
+			sample, err := readSample(f.localPath)
+			if err != nil {
+				return err
+			}
+			payload[i] = preuploadFile{
+				Path:   f.pathInRepo,
+				Sample: base64.StdEncoding.EncodeToString(sample),
+				Size:   f.size,
+			}
+			byPath[f.pathInRepo] = f
+		}
+
+		// Only asks how files will be stored, so it is safe to repeat
+		if err := c.sendJSON(withIdempotent(ctx), http.MethodPost, path, map[string]interface{}{"files": payload}, &result); err != nil {
+			return fmt.Errorf("failed to prepare upload: %w", err)
+		}
+		for _, r := range result.Files {
+			if f, ok := byPath[r.Path]; ok {
+				f.uploadMode = r.UploadMode
+				f.ignore = r.ShouldIgnore
+			}
+		}
+	}
+	return nil
+}
+
+func readSample(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sample := make([]byte, sampleSize)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	return sample[:n], nil
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// Config holds user preferences that are not tied to an account.
+type Config struct {
+	MaxDownloadBytes int64  `json:"max_download_bytes,omitempty"` // Per-job budget; 0 means no limit
+	MaxRate          int64  `json:"max_rate,omitempty"`           // Bandwidth for all transfers in bytes per second; 0 means no limit
+	Proxy            string `json:"proxy,omitempty"`              // Proxy URL, optionally with credentials; overrides HTTPS_PROXY
+	CABundle         string `json:"ca_bundle,omitempty"`          // PEM file with extra root certificates to trust
+}
+
+func getConfigFilePath() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, ".lazyface", "config.json"), nil
+}
+
+// LoadConfig reads the saved configuration, returning defaults when there
+// is none yet.
+func LoadConfig() (*Config, error) {
+	path, err := getConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SaveConfig writes the configuration to disk
+func SaveConfig(config *Config) error {
+	path, err := getConfigFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	errPaused  = errors.New("download paused")
+	errSkipped = errors.New("download skipped")
+)
+
+// DownloadControl lets a caller pause, resume and skip files of a running
+// Download. Create one with NewDownloadControl and pass it in DownloadOptions.
+type DownloadControl struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{} // Closed when a pause ends
+	skipped map[string]bool
+	active  map[string]context.CancelCauseFunc
+	limiter *RateLimiter // Bandwidth of this run, on top of the global limit
+}
+
+func NewDownloadControl() *DownloadControl {
+	return &DownloadControl{
+		skipped: make(map[string]bool),
+		active:  make(map[string]context.CancelCauseFunc),
+		limiter: NewRateLimiter(0),
+	}
+}
+
+// SetRateLimit caps the bandwidth of this run in bytes per second, zero for
+// no limit. Transfers already running slow down or speed up right away.
+func (c *DownloadControl) SetRateLimit(bytesPerSecond int64) {
+	c.limiter.SetLimit(bytesPerSecond)
+}
+
+func (c *DownloadControl) RateLimit() int64 {
+	return c.limiter.Limit()
+}
+
+// Pause interrupts every active transfer. Partial files are kept, so the
+// transfers continue where they stopped once Resume is called.
+func (c *DownloadControl) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return
+	}
+	c.paused = true
+	c.resumed = make(chan struct{})
+	for _, cancel := range c.active {
+		cancel(errPaused)
+	}
+}
+
+func (c *DownloadControl) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	close(c.resumed)
+}
+
+func (c *DownloadControl) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Skip drops file from the run, interrupting it if it is transferring.
+func (c *DownloadControl) Skip(file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skipped[file] = true
+	if cancel, ok := c.active[file]; ok {
+		cancel(errSkipped)
+	}
+}
+
+func (c *DownloadControl) isSkipped(file string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.skipped[file]
+}
+
+// wait blocks while the run is paused.
+func (c *DownloadControl) wait(ctx context.Context) error {
+	c.mu.Lock()
+	paused, resumed := c.paused, c.resumed
+	c.mu.Unlock()
+	if !paused {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// begin returns a context for transferring file that Pause and Skip can
+// interrupt. end must be called once the transfer returns.
+func (c *DownloadControl) begin(ctx context.Context, file string) context.Context {
+	fileCtx, cancel := context.WithCancelCause(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active[file] = cancel
+	if c.skipped[file] {
+		cancel(errSkipped)
+	} else if c.paused {
+		cancel(errPaused)
+	}
+	return fileCtx
+}
+
+func (c *DownloadControl) end(file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cancel, ok := c.active[file]; ok {
+		cancel(nil)
+		delete(c.active, file)
+	}
+}
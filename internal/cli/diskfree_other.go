@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package cli
+
+import "errors"
+
+// diskFree cannot tell the free space on this platform; downloads go ahead
+// without the check.
+func diskFree(dir string) (uint64, error) {
+	return 0, errors.New("free space is unknown on this platform")
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package cli
+
+import "golang.org/x/sys/windows"
+
+// diskFree returns the bytes available to the current user on the volume
+// holding dir.
+func diskFree(dir string) (uint64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, &total, &free); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lowSpaceMargin is how much free space should be left after a download
+// before a warning is shown.
+const lowSpaceMargin = 1 << 30
+
+// SpaceCheck compares the size of a download with the free space at its
+// destination and the configured per-job budget.
+type SpaceCheck struct {
+	Needed int64
+	Free   uint64
+	Budget int64 // 0 when no budget is configured
+}
+
+// Fits reports whether the download fits on the destination filesystem.
+func (s SpaceCheck) Fits() bool {
+	return uint64(s.Needed) <= s.Free
+}
+
+// OverBudget reports whether the download exceeds the configured budget.
+func (s SpaceCheck) OverBudget() bool {
+	return s.Budget > 0 && s.Needed > s.Budget
+}
+
+// LowSpace reports whether the download fits but leaves little room.
+func (s SpaceCheck) LowSpace() bool {
+	return s.Fits() && s.Free-uint64(s.Needed) < lowSpaceMargin
+}
+
+// Blocked returns why the download must not start, or nil.
+func (s SpaceCheck) Blocked() error {
+	if !s.Fits() {
+		return fmt.Errorf("not enough disk space: %s needed, %s free", FormatBytes(s.Needed), FormatBytes(int64(s.Free)))
+	}
+	return s.BudgetExceeded()
+}
+
+// BudgetExceeded returns why the download exceeds the budget, or nil. Unlike
+// Blocked it does not need the free space, so it holds when that is unknown.
+func (s SpaceCheck) BudgetExceeded() error {
+	if s.OverBudget() {
+		return fmt.Errorf("download of %s exceeds the %s budget per job", FormatBytes(s.Needed), FormatBytes(s.Budget))
+	}
+	return nil
+}
+
+// CheckSpace measures free space for downloading needed bytes into dir.
+// dir does not need to exist yet; its closest existing parent is used.
+func CheckSpace(dir string, needed int64) (SpaceCheck, error) {
+	check := SpaceCheck{Needed: needed}
+	if config, err := LoadConfig(); err == nil {
+		check.Budget = config.MaxDownloadBytes
+	}
+
+	existing, err := filepath.Abs(dir)
+	if err != nil {
+		return check, err
+	}
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return check, err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	free, err := diskFree(existing)
+	if err != nil {
+		return check, fmt.Errorf("failed to read free disk space: %w", err)
+	}
+	check.Free = free
+	return check, nil
+}
@@ -0,0 +1,28 @@
+package cli
+
+import "testing"
+
+func TestSpaceCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		check      SpaceCheck
+		blocked    bool
+		overBudget bool
+	}{
+		{"fits without budget", SpaceCheck{Needed: 10, Free: 100}, false, false},
+		{"not enough space", SpaceCheck{Needed: 200, Free: 100}, true, false},
+		{"within budget", SpaceCheck{Needed: 10, Free: 100, Budget: 50}, false, false},
+		{"over budget", SpaceCheck{Needed: 60, Free: 100, Budget: 50}, true, true},
+		{"free space unknown", SpaceCheck{Needed: 60, Budget: 50}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check.Blocked() != nil; got != tt.blocked {
+				t.Errorf("Blocked() = %v, want blocked %v", tt.check.Blocked(), tt.blocked)
+			}
+			if got := tt.check.BudgetExceeded() != nil; got != tt.overBudget {
+				t.Errorf("BudgetExceeded() = %v, want exceeded %v", tt.check.BudgetExceeded(), tt.overBudget)
+			}
+		})
+	}
+}
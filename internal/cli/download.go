@@ -1,42 +1,57 @@
 package cli
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 type RepoInfo struct {
+	Sha      string     `json:"sha"` // Commit the requested revision resolved to
 	Siblings []struct { //siblings is an array of objects
 		Filename string `json:"rfilename"`
 	} `json:"siblings"`
 }
 
-func ListRepoFiles(repoID string) ([]string, error) {
-	url := fmt.Sprintf("https://huggingface.co/api/models/%s", repoID)
+// ListRepoFiles returns the files of a model, dataset or space at revision,
+// with their sizes, hashes and last commits, along with the commit SHA the
+// revision resolved to. The active token is used so private and gated repos can be
+// listed. Retries are reported through updateStatus.
+func ListRepoFiles(repoType, repoID, revision string, updateStatus func(string)) ([]RepoFile, string, error) {
+	ctx := WithRetryStatus(context.Background(), updateStatus)
+	client := DefaultClient()
 
-	resp, err := http.Get(url)
+	_, sha, err := client.ListFiles(ctx, repoType, repoID, revision)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repo info: %w", err)
+		return nil, "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned with status: %s", resp.Status)
+	// List the tree at the resolved commit so it matches what is downloaded
+	pinned := sha
+	if pinned == "" {
+		pinned = revision
 	}
-
-	body, err := io.ReadAll(resp.Body)
+	files, err := client.ListRepoTreeExpanded(ctx, repoType, repoID, pinned)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, "", err
 	}
+	return files, sha, nil
+}
 
+// ListFiles returns the path of every file in the repo at revision and the
+// commit SHA the revision resolved to.
+func (c *Client) ListFiles(ctx context.Context, repoType, repoID, revision string) ([]string, string, error) {
 	var repoInfo RepoInfo
-	if err := json.Unmarshal(body, &repoInfo); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	path := fmt.Sprintf("/api/%s/%s/revision/%s", repoTypePath(repoType), repoID, url.PathEscape(revision))
+	if err := c.getJSON(ctx, path, &repoInfo); err != nil {
+		return nil, "", fmt.Errorf("failed to fetch repo info: %w", err)
 	}
 
 	//Extract filename
@@ -45,17 +60,107 @@ func ListRepoFiles(repoID string) ([]string, error) {
 		files[i] = file.Filename
 	}
 
+	return files, repoInfo.Sha, nil
+}
+
+// RepoFile is a file entry from the repo tree listing.
+type RepoFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	OID  string `json:"oid"` // git blob sha1
+	LFS  *struct {
+		OID  string `json:"oid"` // sha256 of the content
+		Size int64  `json:"size"`
+	} `json:"lfs"`
+	LastCommit *GitCommit `json:"lastCommit"` // Only set by expanded listings
+}
+
+// IsLFS reports whether the file content is stored with Git LFS.
+func (f RepoFile) IsLFS() bool {
+	return f.LFS != nil
+}
+
+// ETag returns the hash the Hub reports for the file's content: the LFS
+// sha256 for LFS files, the git blob sha1 otherwise.
+func (f RepoFile) ETag() string {
+	if f.LFS != nil {
+		return f.LFS.OID
+	}
+	return f.OID
+}
+
+// ListRepoTree returns every file in the repo at revision with its size and hashes.
+func (c *Client) ListRepoTree(ctx context.Context, repoType, repoID, revision string) ([]RepoFile, error) {
+	return c.listTree(ctx, repoType, repoID, revision, false)
+}
+
+// ListRepoTreeExpanded is ListRepoTree with the last commit of every file.
+// The Hub computes those on the fly, so it is slower on large repos.
+func (c *Client) ListRepoTreeExpanded(ctx context.Context, repoType, repoID, revision string) ([]RepoFile, error) {
+	return c.listTree(ctx, repoType, repoID, revision, true)
+}
+
+func (c *Client) listTree(ctx context.Context, repoType, repoID, revision string, expand bool) ([]RepoFile, error) {
+	path := fmt.Sprintf("/api/%s/%s/tree/%s?recursive=true", repoTypePath(repoType), repoID, url.PathEscape(revision))
+	if expand {
+		path += "&expand=true"
+	}
+
+	var files []RepoFile
+	for path != "" {
+		var page []struct {
+			Type string `json:"type"`
+			RepoFile
+		}
+		next, err := c.getPage(ctx, path, &page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repo files: %w", err)
+		}
+		for _, entry := range page {
+			if entry.Type == "file" {
+				files = append(files, entry.RepoFile)
+			}
+		}
+		path = next
+	}
 	return files, nil
 }
 
-func GetDownloadPath(choice string, userPath string, repoId string) (string, error) {
+// pathsInfo returns the files among paths that the repo has at revision.
+// Paths it does not have are left out.
+func (c *Client) pathsInfo(ctx context.Context, repoType, repoID, revision string, paths []string) ([]RepoFile, error) {
+	var entries []struct {
+		Type string `json:"type"`
+		RepoFile
+	}
+	path := fmt.Sprintf("/api/%s/%s/paths-info/%s", repoTypePath(repoType), repoID, url.PathEscape(revision))
+	// Only reads, so it is safe to repeat
+	if err := c.sendJSON(withIdempotent(ctx), http.MethodPost, path, map[string]interface{}{"paths": paths}, &entries); err != nil {
+		return nil, fmt.Errorf("failed to look up repo files: %w", err)
+	}
+	var files []RepoFile
+	for _, entry := range entries {
+		if entry.Type == "file" {
+			files = append(files, entry.RepoFile)
+		}
+	}
+	return files, nil
+}
+
+// DownloadFolder is the folder repos of repoType are grouped under by the
+// default download locations: hfmodels, hfdatasets or hfspaces.
+func DownloadFolder(repoType string) string {
+	return "hf" + repoTypePath(repoType)
+}
+
+func GetDownloadPath(choice string, userPath string, repoType string, repoId string) (string, error) {
 	switch choice {
 	case "Downloads":
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			return "", fmt.Errorf("failed to get home directory: %w", err)
 		}
-		downloadPath := filepath.Join(homeDir, "Downloads", "hfmodels", repoId)
+		downloadPath := filepath.Join(homeDir, "Downloads", DownloadFolder(repoType), repoId)
 		return downloadPath, nil
 
 	case "Current Directory":
@@ -63,8 +168,14 @@ func GetDownloadPath(choice string, userPath string, repoId string) (string, err
 		if err != nil {
 			return "", fmt.Errorf("failed to get current directory: %w", err)
 		}
-		downloadPath := filepath.Join(currentDir, "hfmodels", repoId)
+		downloadPath := filepath.Join(currentDir, DownloadFolder(repoType), repoId)
 		return downloadPath, nil
+	case "HF Cache":
+		cacheDir, err := RepoCacheDir(repoType, repoId)
+		if err != nil {
+			return "", err
+		}
+		return createDirectory(cacheDir)
 	case "custom":
 		if userPath == "" {
 			return "", fmt.Errorf("no custom path provided")
@@ -85,22 +196,463 @@ func createDirectory(path string) (string, error) {
 	return path, nil
 }
 
-func Download(repoID string, files []string, downloadPath string, updateStatus func(string), updateProgress func(float64)) error {
-	noFiles := len(files)
+// DefaultRevision is the branch downloaded when no revision is given.
+const DefaultRevision = "main"
+
+// DefaultConcurrency is the number of files fetched at once when
+// DownloadOptions.Concurrency is not set.
+const DefaultConcurrency = 4
+
+// DownloadOptions tunes how Download fetches files.
+type DownloadOptions struct {
+	RepoType    string           // "model", "dataset" or "space"; a model if empty
+	Revision    string           // Branch, tag or commit to download; DefaultRevision if empty
+	Filter      FileFilter       // Only files passing it are downloaded
+	Concurrency int              // Maximum number of files transferred at once
+	Control     *DownloadControl // Optional handle to pause, resume and skip files
+	CacheLayout bool             // Store files like huggingface_hub: blobs, snapshots and refs
+	Ref         string           // Branch or tag Revision was resolved from, recorded under refs/
+}
+
+// FileError records why a single file failed to download.
+type FileError struct {
+	File string
+	Err  error
+}
+
+// DownloadError summarises the files that failed during a Download run.
+type DownloadError struct {
+	Failed []FileError
+	Total  int
+}
+
+func (e *DownloadError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d files failed", len(e.Failed), e.Total)
+	for _, f := range e.Failed {
+		fmt.Fprintf(&b, "\n  %s: %v", f.File, f.Err)
+	}
+	return b.String()
+}
+
+// Unwrap exposes the per-file errors so callers can match them with errors.Is.
+func (e *DownloadError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// progressInterval is how often byte-level progress is reported while files
+// are transferring.
+const progressInterval = 200 * time.Millisecond
+
+// Download fetches files concurrently. Sizes are resolved first so progress
+// is reported in bytes, and every finished file is verified against the
+// Hub's hash before it counts as done. Files already present with the same
+// size and hash are left alone, so repeating a download only fetches what
+// changed. A failing file does not stop the others; all failures are
+// returned together as a *DownloadError.
+func Download(ctx context.Context, repoID string, files []string, downloadPath string, opts DownloadOptions, updateStatus func(string), updateProgress func(DownloadProgress)) error {
+	if opts.Control == nil {
+		opts.Control = NewDownloadControl()
+	}
+	if opts.RepoType == "" {
+		opts.RepoType = "model"
+	}
+	if opts.Revision == "" {
+		opts.Revision = DefaultRevision
+	}
+	files = opts.Filter.Apply(files)
+	ctx = WithRetryStatus(ctx, updateStatus)
+	run := &downloadRun{
+		client:         DefaultClient(),
+		repoID:         repoID,
+		downloadPath:   downloadPath,
+		opts:           opts,
+		updateStatus:   updateStatus,
+		updateProgress: updateProgress,
+		files:          make([]FileProgress, len(files)),
+		etags:          make([]string, len(files)),
+	}
 	for i, file := range files {
-		updateStatus(fmt.Sprintf("Downloading File %d/%d: %s", i+1, len(files), file))
+		run.files[i] = FileProgress{Name: file}
+	}
+	return run.execute(ctx)
+}
+
+// downloadRun holds the shared state of one Download call.
+type downloadRun struct {
+	client         *Client
+	repoID         string
+	downloadPath   string
+	opts           DownloadOptions
+	updateStatus   func(string)
+	updateProgress func(DownloadProgress)
+
+	mu     sync.Mutex
+	files  []FileProgress
+	etags  []string
+	commit string // Commit the revision resolved to, named by the Hub
+	meter  rateMeter
+	blobs  sync.Map // ETag to *sync.Mutex, guarding blobs shared by several files
+}
 
-		args := []string{"download", repoID, "--local-dir", downloadPath, "--include", file}
-		cmd := exec.Command("huggingface-cli", args...)
-		output, err := cmd.CombinedOutput()
+func (r *downloadRun) execute(ctx context.Context) error {
+	noFiles := len(r.files)
+	workers := r.opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+	if workers > noFiles {
+		workers = noFiles
+	}
+
+	// Resolve sizes up front so the overall total is known from the start
+	r.updateStatus(fmt.Sprintf("Resolving %d files...", noFiles))
+	r.forEach(ctx, workers, func(i int) {
+		meta, err := r.client.FileMetadata(ctx, r.opts.RepoType, r.repoID, r.opts.Revision, r.files[i].Name)
+		r.mu.Lock()
+		defer r.mu.Unlock()
 		if err != nil {
-			return fmt.Errorf("failed to download file %s: %v\nOutput: %s", file, err, string(output))
+			r.files[i].State = FileFailed
+			r.files[i].Err = err
+			return
+		}
+		if meta.Size > 0 {
+			r.files[i].BytesTotal = meta.Size
+		}
+		r.etags[i] = meta.ETag
+		if r.commit == "" {
+			r.commit = meta.Commit
+		}
+	})
+	r.report()
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	r.forEach(ctx, workers, func(i int) {
+		r.fetch(ctx, i)
+	})
+
+	close(stop)
+	<-stopped
+	r.report()
+
+	if r.opts.CacheLayout && r.count(FileDone)+r.count(FileUpToDate) > 0 {
+		if err := r.writeRef(); err != nil {
+			r.updateStatus(fmt.Sprintf("Error: %v", err))
+			return err
+		}
+	}
+
+	var failed []FileError
+	for _, f := range r.files {
+		if f.State == FileFailed {
+			failed = append(failed, FileError{File: f.Name, Err: f.Err})
 		}
-		progress := (float64(i+1) / float64(noFiles))
-		updateProgress(progress)
+	}
+	if len(failed) > 0 {
+		sort.Slice(failed, func(i, j int) bool { return failed[i].File < failed[j].File })
+		r.updateStatus(fmt.Sprintf("Download finished with errors: %d of %d files failed.", len(failed), noFiles))
+		return &DownloadError{Failed: failed, Total: noFiles}
+	}
+
+	if ctx.Err() != nil {
+		r.updateStatus("Download canceled.")
+		return ctx.Err()
 	}
 
-	updateStatus(fmt.Sprintf("Download complete! %d files downloaded.", len(files)))
-	updateProgress(1.0)
+	status := fmt.Sprintf("Download complete! %d files downloaded.", r.count(FileDone))
+	if unchanged := r.count(FileUpToDate); unchanged > 0 {
+		status += fmt.Sprintf(" %d up to date.", unchanged)
+	}
+	if skipped := r.count(FileSkipped); skipped > 0 {
+		status += fmt.Sprintf(" %d skipped.", skipped)
+	}
+	r.updateStatus(status)
 	return nil
 }
+
+func (r *downloadRun) count(state FileState) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, f := range r.files {
+		if f.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// fetch transfers and verifies file i, recording the outcome. Pausing
+// interrupts the transfer, which is then resumed from its partial file.
+func (r *downloadRun) fetch(ctx context.Context, i int) {
+	control := r.opts.Control
+
+	r.mu.Lock()
+	file, etag := r.files[i].Name, r.etags[i]
+	if r.files[i].State == FileFailed {
+		r.mu.Unlock()
+		return
+	}
+	if r.opts.CacheLayout && (etag == "" || r.snapshotCommit() == "") {
+		r.files[i].State = FileFailed
+		r.files[i].Err = fmt.Errorf("the Hub did not report a hash and commit for %s, which the cache layout needs", file)
+		status := r.statusLocked("Failed", fmt.Sprintf("%s: %v", file, r.files[i].Err))
+		r.mu.Unlock()
+		r.updateStatus(status)
+		return
+	}
+	if control.isSkipped(file) {
+		r.files[i].State = FileSkipped
+		status := r.statusLocked("Skipped", file)
+		r.mu.Unlock()
+		r.updateStatus(status)
+		return
+	}
+	r.mu.Unlock()
+
+	// In the cache layout identical content is stored once, so a blob
+	// another file or revision already brought in is only linked
+	local := r.localName(file, etag)
+	if r.opts.CacheLayout {
+		blob, _ := r.blobs.LoadOrStore(etag, &sync.Mutex{})
+		blob.(*sync.Mutex).Lock()
+		defer blob.(*sync.Mutex).Unlock()
+	}
+	if r.present(i, local) {
+		r.mu.Lock()
+		r.files[i].BytesDone = r.files[i].BytesTotal
+		r.files[i].ResumedFrom = r.files[i].BytesTotal
+		r.mu.Unlock()
+		r.finish(ctx, i, true, nil, nil)
+		return
+	}
+
+	var err, cause error
+	for {
+		if err = control.wait(ctx); err != nil {
+			break
+		}
+
+		r.mu.Lock()
+		r.files[i].State = FileActive
+		resumed := PartialSize(r.downloadPath, local, etag)
+		r.files[i].BytesDone = resumed
+		r.files[i].ResumedFrom = resumed
+		status := r.statusLocked("Downloading", file)
+		if resumed > 0 {
+			status = r.statusLocked("Resuming", fmt.Sprintf("%s from %s", file, FormatBytes(resumed)))
+		}
+		r.mu.Unlock()
+		r.updateStatus(status)
+
+		fileCtx := control.begin(ctx, file)
+		err = r.client.downloadTo(fileCtx, r.opts.RepoType, r.repoID, r.opts.Revision, file, filepath.Join(r.downloadPath, filepath.FromSlash(local)), etag, control.limiter, func(n int64) {
+			r.mu.Lock()
+			r.files[i].BytesDone += n
+			r.mu.Unlock()
+		})
+		cause = context.Cause(fileCtx)
+		control.end(file)
+
+		if err != nil && cause == errPaused && ctx.Err() == nil {
+			r.mu.Lock()
+			r.files[i].State = FileQueued
+			status := r.statusLocked("Paused", file)
+			r.mu.Unlock()
+			r.updateStatus(status)
+			continue
+		}
+		break
+	}
+
+	if err == nil {
+		r.mu.Lock()
+		r.files[i].State = FileVerifying
+		status := r.statusLocked("Verifying", file)
+		r.mu.Unlock()
+		r.updateStatus(status)
+		err = r.verify(file, local, etag)
+	}
+	r.finish(ctx, i, false, err, cause)
+}
+
+// present reports whether file i is already stored at local with the
+// content the Hub has, so it need not be transferred again.
+func (r *downloadRun) present(i int, local string) bool {
+	r.mu.Lock()
+	size, etag := r.files[i].BytesTotal, r.etags[i]
+	r.mu.Unlock()
+	path := filepath.Join(r.downloadPath, filepath.FromSlash(local))
+	if r.opts.CacheLayout {
+		return fileExists(path)
+	}
+	return etag != "" && upToDate(path, size, etag)
+}
+
+// finish links a stored file into its snapshot when using the cache layout
+// and records the outcome of file i. unchanged marks a file that was
+// already present and not transferred.
+func (r *downloadRun) finish(ctx context.Context, i int, unchanged bool, err, cause error) {
+	control := r.opts.Control
+	file := r.files[i].Name
+	if err == nil && r.opts.CacheLayout {
+		err = linkSnapshot(r.downloadPath, r.snapshotCommit(), file, r.etags[i])
+	}
+
+	var status string
+	r.mu.Lock()
+	switch {
+	case err == nil && unchanged:
+		r.files[i].State = FileUpToDate
+		status = r.statusLocked("Up to date", file)
+	case err == nil:
+		r.files[i].State = FileDone
+		if r.files[i].BytesTotal < r.files[i].BytesDone {
+			r.files[i].BytesTotal = r.files[i].BytesDone
+		}
+		status = r.statusLocked("Finished", file)
+	case cause == errSkipped || control.isSkipped(file):
+		r.files[i].State = FileSkipped
+		status = r.statusLocked("Skipped", file)
+	case ctx.Err() != nil:
+		// Canceled as a whole; the partial file stays for a later resume
+		r.files[i].State = FileQueued
+	default:
+		r.files[i].State = FileFailed
+		r.files[i].Err = err
+		status = r.statusLocked("Failed", fmt.Sprintf("%s: %v", file, err))
+	}
+	r.mu.Unlock()
+	if status != "" {
+		r.updateStatus(status)
+	}
+}
+
+// localName is where the content of file is stored, relative to the
+// download path: the repo path itself, or its blob in the cache layout.
+func (r *downloadRun) localName(file, etag string) string {
+	if r.opts.CacheLayout {
+		return blobPath(etag)
+	}
+	return file
+}
+
+// snapshotCommit names the snapshot folder files are linked into.
+func (r *downloadRun) snapshotCommit() string {
+	if r.commit != "" {
+		return r.commit
+	}
+	if isCommitSHA(r.opts.Revision) {
+		return r.opts.Revision
+	}
+	return ""
+}
+
+// writeRef points the branch or tag that was downloaded at its snapshot.
+// A revision that is the commit itself needs no ref.
+func (r *downloadRun) writeRef() error {
+	ref := r.opts.Ref
+	if ref == "" {
+		ref = r.opts.Revision
+	}
+	if isCommitSHA(ref) {
+		return nil
+	}
+	return writeRef(r.downloadPath, ref, r.snapshotCommit())
+}
+
+// verify checks a finished file against its ETag and quarantines it on a
+// mismatch, so a corrupt copy never sits at the final path.
+func (r *downloadRun) verify(file, local, etag string) error {
+	err := VerifyFile(filepath.Join(r.downloadPath, filepath.FromSlash(local)), etag)
+	var checksumErr *ChecksumError
+	if !errors.As(err, &checksumErr) {
+		return err
+	}
+	checksumErr.File = file
+	if moved, qErr := quarantine(r.downloadPath, local, r.opts.CacheLayout); qErr == nil {
+		checksumErr.Quarantined = moved
+	}
+	return checksumErr
+}
+
+// forEach runs fn for every file index using at most workers goroutines.
+// No new files are started once ctx is canceled.
+func (r *downloadRun) forEach(ctx context.Context, workers int, fn func(i int)) {
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+dispatch:
+	for i := range r.files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// statusLocked formats a status line; r.mu must be held. updateStatus may
+// block until the UI reads it, so send the line only after unlocking.
+func (r *downloadRun) statusLocked(action, detail string) string {
+	done, active := 0, 0
+	for _, f := range r.files {
+		switch f.State {
+		case FileDone, FileFailed, FileSkipped, FileUpToDate:
+			done++
+		case FileActive:
+			active++
+		}
+	}
+	return fmt.Sprintf("[%d/%d done, %d active] %s %s", done, len(r.files), active, action, detail)
+}
+
+// report sends a snapshot of the current progress to updateProgress.
+func (r *downloadRun) report() {
+	r.mu.Lock()
+	progress := DownloadProgress{Files: make([]FileProgress, len(r.files))}
+	copy(progress.Files, r.files)
+	var resumed int64
+	for _, f := range r.files {
+		progress.BytesDone += f.BytesDone
+		progress.BytesTotal += f.BytesTotal
+		resumed += f.ResumedFrom
+	}
+	// Bytes picked up from partial files were not transferred now
+	progress.Rate = r.meter.sample(progress.BytesDone-resumed, time.Now())
+	r.mu.Unlock()
+
+	if progress.Rate > 0 && progress.BytesTotal > progress.BytesDone {
+		progress.ETA = time.Duration(float64(progress.BytesTotal-progress.BytesDone) / progress.Rate * float64(time.Second))
+	}
+	r.updateProgress(progress)
+}
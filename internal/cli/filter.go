@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxSavedFilters bounds how many recently used filters are remembered.
+const maxSavedFilters = 20
+
+// FileFilter selects repo files with glob patterns. A file passes when it
+// matches any include pattern (or there are none) and no exclude pattern.
+type FileFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// ParseFilter reads a comma or space separated list of patterns. Patterns
+// starting with '!' exclude, e.g. "*.safetensors !*onnx* tokenizer*".
+func ParseFilter(s string) FileFilter {
+	var f FileFilter
+	for _, pattern := range splitPatterns(s) {
+		if strings.HasPrefix(pattern, "!") {
+			if pattern = pattern[1:]; pattern != "" {
+				f.Exclude = append(f.Exclude, pattern)
+			}
+		} else {
+			f.Include = append(f.Include, pattern)
+		}
+	}
+	return f
+}
+
+// String formats the filter the way ParseFilter reads it.
+func (f FileFilter) String() string {
+	patterns := append([]string{}, f.Include...)
+	for _, p := range f.Exclude {
+		patterns = append(patterns, "!"+p)
+	}
+	return strings.Join(patterns, " ")
+}
+
+func (f FileFilter) Empty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Included reports whether file matches an include pattern.
+func (f FileFilter) Included(file string) bool {
+	return matchAny(f.Include, file)
+}
+
+// Excluded reports whether file matches an exclude pattern.
+func (f FileFilter) Excluded(file string) bool {
+	return matchAny(f.Exclude, file)
+}
+
+// Match reports whether file passes the filter.
+func (f FileFilter) Match(file string) bool {
+	return (len(f.Include) == 0 || f.Included(file)) && !f.Excluded(file)
+}
+
+// Apply returns the files that pass the filter, in order.
+func (f FileFilter) Apply(files []string) []string {
+	if f.Empty() {
+		return files
+	}
+	var matched []string
+	for _, file := range files {
+		if f.Match(file) {
+			matched = append(matched, file)
+		}
+	}
+	return matched
+}
+
+func getFiltersFilePath() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, ".lazyface", "filters.json"), nil
+}
+
+// LoadSavedFilters returns recently used filters, most recent first.
+func LoadSavedFilters() ([]string, error) {
+	path, err := getFiltersFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var filters []string
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+// SaveFilter moves filter to the front of the saved filters.
+func SaveFilter(filter string) error {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil
+	}
+	filters, err := LoadSavedFilters()
+	if err != nil {
+		return err
+	}
+
+	saved := []string{filter}
+	for _, f := range filters {
+		if f != filter && len(saved) < maxSavedFilters {
+			saved = append(saved, f)
+		}
+	}
+
+	path, err := getFiltersFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(saved, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// splitPatterns splits a comma or space separated list of patterns.
+func splitPatterns(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if fnmatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fnmatch reports whether name matches the shell pattern. Unlike path.Match,
+// '*' also matches '/', mirroring the Python fnmatch semantics that
+// huggingface_hub uses for its include/exclude patterns.
+func fnmatch(pattern, name string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; ch {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		input string
+		want  FileFilter
+	}{
+		{"", FileFilter{}},
+		{"*.safetensors", FileFilter{Include: []string{"*.safetensors"}}},
+		{"*.safetensors !*onnx* tokenizer*", FileFilter{Include: []string{"*.safetensors", "tokenizer*"}, Exclude: []string{"*onnx*"}}},
+		{"*.json,*.txt, !README.md", FileFilter{Include: []string{"*.json", "*.txt"}, Exclude: []string{"README.md"}}},
+		{"! !*.bin", FileFilter{Exclude: []string{"*.bin"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := ParseFilter(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			if again := ParseFilter(got.String()); !reflect.DeepEqual(again, got) {
+				t.Errorf("ParseFilter(%q) = %+v after String, want %+v", got.String(), again, got)
+			}
+		})
+	}
+}
+
+func TestFileFilterMatch(t *testing.T) {
+	tests := []struct {
+		filter string
+		file   string
+		want   bool
+	}{
+		{"", "model.safetensors", true},
+		{"*.safetensors", "model.safetensors", true},
+		{"*.safetensors", "onnx/model.safetensors", true},
+		{"*.safetensors", "model.bin", false},
+		{"!*.bin", "model.bin", false},
+		{"!*.bin", "config.json", true},
+		{"*.safetensors !*onnx*", "onnx/model.safetensors", false},
+		{"*.safetensors !*onnx*", "model-00001-of-00002.safetensors", true},
+		{"tokenizer*", "tokenizer_config.json", true},
+		{"tokenizer*", "sub/tokenizer.json", false},
+	}
+	for _, tt := range tests {
+		if got := ParseFilter(tt.filter).Match(tt.file); got != tt.want {
+			t.Errorf("ParseFilter(%q).Match(%q) = %v, want %v", tt.filter, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestFileFilterApply(t *testing.T) {
+	files := []string{"config.json", "model.bin", "model.safetensors", "onnx/model.onnx"}
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", files},
+		{"*.safetensors *.json", []string{"config.json", "model.safetensors"}},
+		{"!onnx/*", []string{"config.json", "model.bin", "model.safetensors"}},
+		{"*.gguf", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseFilter(tt.filter).Apply(files); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFilter(%q).Apply() = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestFnmatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*", "a/b/c.txt", true},
+		{"*.txt", "notes.txt", true},
+		{"*.txt", "docs/notes.txt", true},
+		{"*.txt", "notes.txt.bak", false},
+		{"docs/*", "docs/a/b.md", true},
+		{"?.bin", "a.bin", true},
+		{"?.bin", "ab.bin", false},
+		{"model-[0-9].bin", "model-3.bin", true},
+		{"model-[0-9].bin", "model-x.bin", false},
+		{"model-[!0-9].bin", "model-x.bin", true},
+		{"model-[!0-9].bin", "model-3.bin", false},
+		{"[abc", "[abc", true},
+		{"a.b", "axb", false},
+		{"(x)+.json", "(x)+.json", true},
+		{"README.md", "README.md", true},
+		{"README.md", "docs/README.md", false},
+	}
+	for _, tt := range tests {
+		if got := fnmatch(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("fnmatch(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
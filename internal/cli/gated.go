@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// AccessStatus is where the current user stands with a gated repo.
+type AccessStatus string
+
+const (
+	AccessGranted       AccessStatus = "accepted"
+	AccessNotRequested  AccessStatus = "not requested"
+	AccessPending       AccessStatus = "pending"
+	AccessRejected      AccessStatus = "rejected"
+	AccessLoginRequired AccessStatus = "login required"
+)
+
+// GateField is an extra field the repo owner asks requesters to fill in.
+type GateField struct {
+	Name    string
+	Type    string   // "text", "checkbox", "date_picker", "country" or "select"
+	Options []string // Choices of a "select" field
+}
+
+// GateInfo describes the access gate of a repo and the user's status.
+type GateInfo struct {
+	Mode          string // "auto" or "manual"; empty when the repo is not gated
+	Heading       string
+	Prompt        string
+	ButtonContent string
+	Fields        []GateField
+	Status        AccessStatus
+}
+
+// gatedRepoInfo is the part of the repo info endpoint describing the gate.
+type gatedRepoInfo struct {
+	Gated    interface{} `json:"gated"` // false, "auto" or "manual"
+	CardData struct {
+		Heading       string                 `json:"extra_gated_heading"`
+		Prompt        string                 `json:"extra_gated_prompt"`
+		ButtonContent string                 `json:"extra_gated_button_content"`
+		Fields        map[string]interface{} `json:"extra_gated_fields"`
+	} `json:"cardData"`
+}
+
+// Gate fetches the access gate of a repo. For gated repos the user's status
+// is found by probing one of files, since the Hub only reports it when a
+// gated file is requested.
+func (c *Client) Gate(ctx context.Context, repoType, repoID, revision string, files []string) (*GateInfo, error) {
+	var info gatedRepoInfo
+	path := fmt.Sprintf("/api/%s/%s", repoTypePath(repoType), repoID)
+	if err := c.getJSON(ctx, path, &info); err != nil {
+		return nil, fmt.Errorf("failed to fetch repo info: %w", err)
+	}
+
+	gate := &GateInfo{
+		Heading:       info.CardData.Heading,
+		Prompt:        info.CardData.Prompt,
+		ButtonContent: info.CardData.ButtonContent,
+		Fields:        gateFields(info.CardData.Fields),
+		Status:        AccessGranted,
+	}
+	if mode, ok := info.Gated.(string); ok {
+		gate.Mode = mode
+	}
+	if gate.Mode == "" {
+		return gate, nil
+	}
+
+	probe := gateProbe(files)
+	if probe == "" {
+		return gate, nil
+	}
+	_, err := c.FileMetadata(ctx, repoType, repoID, revision, probe)
+	var hubErr *HubError
+	switch {
+	case err == nil:
+		gate.Status = AccessGranted
+	case errors.As(err, &hubErr) && (errors.Is(err, ErrGatedRepo) || errors.Is(err, ErrLoginRequired)):
+		gate.Status = accessStatus(hubErr)
+	default:
+		return nil, err
+	}
+	return gate, nil
+}
+
+// gateProbe picks a file the gate applies to. The README and git attributes
+// of gated repos stay public, so they tell nothing about access.
+func gateProbe(files []string) string {
+	for _, f := range files {
+		if f != "README.md" && f != ".gitattributes" {
+			return f
+		}
+	}
+	return ""
+}
+
+// accessStatus reads the user's status out of the Hub's gated repo message.
+func accessStatus(err *HubError) AccessStatus {
+	msg := strings.ToLower(err.Message)
+	switch {
+	case err.Anonymous:
+		return AccessLoginRequired
+	case strings.Contains(msg, "awaiting a review"):
+		return AccessPending
+	case strings.Contains(msg, "rejected"):
+		return AccessRejected
+	}
+	return AccessNotRequested
+}
+
+// gateFields converts extra_gated_fields, whose values are either a type
+// name or an object such as {"type": "select", "options": [...]}.
+func gateFields(raw map[string]interface{}) []GateField {
+	fields := make([]GateField, 0, len(raw))
+	for name, spec := range raw {
+		field := GateField{Name: name, Type: "text"}
+		switch s := spec.(type) {
+		case string:
+			field.Type = s
+		case map[string]interface{}:
+			if t, ok := s["type"].(string); ok {
+				field.Type = t
+			}
+			if options, ok := s["options"].([]interface{}); ok {
+				for _, o := range options {
+					switch o := o.(type) {
+					case string:
+						field.Options = append(field.Options, o)
+					case map[string]interface{}:
+						if v, ok := o["value"].(string); ok {
+							field.Options = append(field.Options, v)
+						}
+					}
+				}
+			}
+		}
+		fields = append(fields, field)
+	}
+	// The card is a YAML map, so its order is lost; keep it stable at least
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// RequestAccess submits the access request form of a gated repo with the
+// given answers to its extra fields.
+func (c *Client) RequestAccess(ctx context.Context, repoType, repoID string, answers map[string]string) error {
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+
+	form := url.Values{}
+	for name, value := range answers {
+		form.Set(name, value)
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, repoURLPrefix(repoType, repoID)+"/ask-access", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.do(req)
+	var hubErr *HubError
+	if errors.As(err, &hubErr) && hubErr.StatusCode >= 400 && hubErr.StatusCode < 500 && hubErr.StatusCode != http.StatusTooManyRequests {
+		// The Hub explains what is wrong with the request, e.g. a missing
+		// answer; that beats the generic message for its status
+		if hubErr.Message == "" {
+			return fmt.Errorf("the Hub refused the access request: %s", http.StatusText(hubErr.StatusCode))
+		}
+		return fmt.Errorf("the Hub refused the access request: %s", hubErr.Message)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to request access: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// RepoGate returns the gate of a repo for the logged in user. Retries are
+// reported through updateStatus.
+func RepoGate(repoType, repoID, revision string, files []string, updateStatus func(string)) (*GateInfo, error) {
+	return DefaultClient().Gate(WithRetryStatus(context.Background(), updateStatus), repoType, repoID, revision, files)
+}
+
+// RequestRepoAccess asks for access to a gated repo as the logged in user.
+// Retries are reported through updateStatus.
+func RequestRepoAccess(repoType, repoID string, answers map[string]string, updateStatus func(string)) error {
+	client := DefaultClient()
+	if client.Token == "" {
+		return ErrLoginRequired
+	}
+	return client.RequestAccess(WithRetryStatus(context.Background(), updateStatus), repoType, repoID, answers)
+}
@@ -0,0 +1,436 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Bounds of every commit of a large upload. A failure only loses the commit
+// in progress, and LFS files it already stored are not sent again.
+const (
+	largeCommitFiles = 100
+	largeCommitBytes = 10 << 30
+)
+
+// largeUploadSaveInterval is how often hashing progress is saved.
+const largeUploadSaveInterval = 5 * time.Second
+
+// LargeUpload is a folder upload split into several commits. Its state is
+// saved after every step, so running it again continues where it stopped.
+type LargeUpload struct {
+	ID            string             `json:"id"`
+	RepoID        string             `json:"repo_id"`
+	RepoType      string             `json:"repo_type"`
+	Revision      string             `json:"revision"`
+	LocalPath     string             `json:"local_path"`
+	PathInRepo    string             `json:"path_in_repo"`
+	Include       string             `json:"include,omitempty"`
+	Exclude       string             `json:"exclude,omitempty"`
+	CommitMessage string             `json:"commit_message"`
+	Files         []*LargeUploadFile `json:"files"`
+	Commits       []CommitInfo       `json:"commits,omitempty"`
+	Done          bool               `json:"done"`            // Done uploads are no longer saved
+	Error         string             `json:"error,omitempty"` // Why the last run stopped
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// LargeUploadFile is the recorded state of one file of a LargeUpload.
+type LargeUploadFile struct {
+	Path      string    `json:"path"` // In the repo
+	Local     string    `json:"local"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	SHA256    string    `json:"sha256,omitempty"` // Only for LFS files, empty until hashed
+	Committed bool      `json:"committed"`
+}
+
+// NewLargeUpload returns an upload of the folder at localPath to pathInRepo
+// that has not run yet. Empty options get the same defaults as
+// UploadToHuggingFace.
+func NewLargeUpload(repoID, localPath, pathInRepo, repoType, includePattern, excludePattern, commitMessage, revision string) *LargeUpload {
+	if repoType == "" {
+		repoType = "model"
+	}
+	if revision == "" {
+		revision = "main"
+	}
+	if commitMessage == "" {
+		commitMessage = "Upload with Lazyface"
+	}
+	return &LargeUpload{
+		ID:            strconv.FormatInt(time.Now().UnixNano(), 36),
+		RepoID:        repoID,
+		RepoType:      repoType,
+		Revision:      revision,
+		LocalPath:     localPath,
+		PathInRepo:    pathInRepo,
+		Include:       includePattern,
+		Exclude:       excludePattern,
+		CommitMessage: commitMessage,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// Committed returns how many files are in a commit on the Hub.
+func (u *LargeUpload) Committed() int {
+	n := 0
+	for _, f := range u.Files {
+		if f.Committed {
+			n++
+		}
+	}
+	return n
+}
+
+// RunLargeUpload commits the files of upload in parts of at most
+// largeCommitFiles files and largeCommitBytes bytes, saving the state after
+// every step. Files committed by an earlier run are skipped unless
+// they changed since. Progress is reported like UploadToHuggingFace's.
+func RunLargeUpload(upload *LargeUpload, limiter *RateLimiter, updateStatus func(string), updateProgress func(DownloadProgress)) error {
+	tracker := newUploadTracker(updateStatus, updateProgress)
+	ctx := WithRetryStatus(context.Background(), tracker.updateStatus)
+
+	err := upload.run(ctx, DefaultClient(), limiter, tracker)
+	upload.Error = ""
+	if err != nil {
+		upload.Error = err.Error()
+	} else {
+		upload.Done = true
+	}
+	if saveErr := SaveLargeUpload(upload); saveErr != nil && err == nil {
+		err = fmt.Errorf("failed to save upload state: %w", saveErr)
+	}
+	return err
+}
+
+func (u *LargeUpload) run(ctx context.Context, client *Client, limiter *RateLimiter, tracker *uploadTracker) error {
+	tracker.status(fmt.Sprintf("Scanning %s...", u.LocalPath))
+	scanned, err := collectUploadFiles(u.LocalPath, u.PathInRepo, splitPatterns(u.Include), splitPatterns(u.Exclude))
+	if err != nil {
+		return &UploadError{Step: "preparing", Err: fmt.Errorf("failed to read local files: %w", err)}
+	}
+	if err := u.refresh(scanned); err != nil {
+		return &UploadError{Step: "preparing", Err: err}
+	}
+	if len(u.Files) == 0 {
+		return fmt.Errorf("no files matched, nothing to upload")
+	}
+	if err := SaveLargeUpload(u); err != nil {
+		return fmt.Errorf("failed to save upload state: %w", err)
+	}
+
+	files := make([]*commitFile, len(u.Files))
+	for i, record := range u.Files {
+		files[i] = &commitFile{localPath: record.Local, pathInRepo: record.Path, size: record.Size, sha256: record.SHA256}
+	}
+	tracker.track(files)
+	for i, record := range u.Files {
+		if record.Committed {
+			tracker.setState(files[i], FileDone)
+		}
+	}
+	stop := tracker.start()
+	defer stop()
+
+	batches := u.batches()
+	for n, batch := range batches {
+		part := len(u.Commits) + 1
+		tracker.prefix = fmt.Sprintf("[part %d of %d] ", part, len(u.Commits)+len(batches)-n)
+
+		// A run that stopped between a commit and saving it must not send
+		// the same files again; the Hub rejects commits that change nothing
+		pending, err := u.alreadyCommitted(ctx, client, batch, files, tracker)
+		if err != nil {
+			return err
+		}
+		if len(pending) < len(batch) {
+			if err := SaveLargeUpload(u); err != nil {
+				return fmt.Errorf("failed to save upload state: %w", err)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		batch = pending
+
+		batchFiles := make([]*commitFile, len(batch))
+		for i, index := range batch {
+			batchFiles[i] = files[index]
+		}
+		// Only LFS files are committed by hash, and the Hub tells which
+		// those are; createCommit then reuses the saved hashes
+		tracker.status(fmt.Sprintf("Preparing %d files...", len(batch)))
+		if err := client.preupload(ctx, u.RepoType, u.RepoID, u.Revision, batchFiles); err != nil {
+			return &UploadError{Step: "preparing", Err: err}
+		}
+		if err := u.hash(batch, files, tracker); err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("%s (part %d)", u.CommitMessage, part)
+		commit, err := client.createCommit(ctx, u.RepoType, u.RepoID, u.Revision, message, batchFiles, nil, limiter, tracker)
+		if err != nil {
+			return err
+		}
+
+		for _, index := range batch {
+			u.Files[index].Committed = true
+		}
+		u.Commits = append(u.Commits, CommitInfo{SHA: commit.CommitOID, URL: commit.CommitURL})
+		if err := SaveLargeUpload(u); err != nil {
+			return fmt.Errorf("failed to save upload state: %w", err)
+		}
+	}
+	tracker.prefix = ""
+	return nil
+}
+
+// alreadyCommitted marks the files of batch that the revision already has
+// with the same content as committed, and returns the others.
+func (u *LargeUpload) alreadyCommitted(ctx context.Context, client *Client, batch []int, files []*commitFile, tracker *uploadTracker) ([]int, error) {
+	paths := make([]string, len(batch))
+	for i, index := range batch {
+		paths[i] = u.Files[index].Path
+	}
+	remote, err := client.pathsInfo(ctx, u.RepoType, u.RepoID, u.Revision, paths)
+	if err != nil {
+		return nil, &UploadError{Step: "preparing", Err: err}
+	}
+	byPath := make(map[string]RepoFile, len(remote))
+	for _, f := range remote {
+		byPath[f.Path] = f
+	}
+
+	var pending []int
+	for _, index := range batch {
+		record := u.Files[index]
+		same := false
+		if f, ok := byPath[record.Path]; ok {
+			if f.IsLFS() && record.SHA256 == "" {
+				tracker.status(fmt.Sprintf("Hashing %s to compare it with the Hub...", record.Path))
+			}
+			same = record.sameContent(f)
+		}
+		// Comparing with an LFS file may have hashed it, which is kept for the commit
+		files[index].sha256 = record.SHA256
+		if same {
+			record.Committed = true
+			tracker.setState(files[index], FileUpToDate)
+			continue
+		}
+		pending = append(pending, index)
+	}
+	return pending, nil
+}
+
+// sameContent reports whether the recorded file has the content of the
+// repo file remote. The sha256 of an LFS file is computed when it is not
+// known yet and recorded, so the file is not hashed again for the commit.
+func (f *LargeUploadFile) sameContent(remote RepoFile) bool {
+	if !remote.IsLFS() {
+		return remote.ETag() != "" && upToDate(f.Local, f.Size, remote.ETag())
+	}
+	if remote.LFS.Size != f.Size {
+		return false
+	}
+	if f.SHA256 == "" {
+		sum, err := sha256File(f.Local)
+		if err != nil {
+			return false
+		}
+		f.SHA256 = sum
+	}
+	return remote.LFS.OID == f.SHA256
+}
+
+// refresh replaces the recorded files with the scanned ones, keeping what
+// is known about files that did not change since they were recorded.
+func (u *LargeUpload) refresh(scanned []*commitFile) error {
+	known := make(map[string]*LargeUploadFile, len(u.Files))
+	for _, record := range u.Files {
+		known[record.Path] = record
+	}
+
+	files := make([]*LargeUploadFile, 0, len(scanned))
+	for _, f := range scanned {
+		info, err := os.Stat(f.localPath)
+		if err != nil {
+			return err
+		}
+		record, ok := known[f.pathInRepo]
+		if !ok || record.Size != info.Size() || !record.ModTime.Equal(info.ModTime()) {
+			record = &LargeUploadFile{Path: f.pathInRepo, Local: f.localPath, Size: info.Size(), ModTime: info.ModTime()}
+		}
+		files = append(files, record)
+	}
+	u.Files = files
+	return nil
+}
+
+// hash computes the sha256 of the files of batch that go through LFS,
+// saving the hashes as it goes so a restart does not compute them again.
+func (u *LargeUpload) hash(batch []int, files []*commitFile, tracker *uploadTracker) error {
+	var pending []int
+	for _, i := range batch {
+		if files[i].uploadMode == "lfs" && !files[i].ignore && u.Files[i].SHA256 == "" {
+			pending = append(pending, i)
+		}
+	}
+
+	lastSave := time.Now()
+	for n, i := range pending {
+		record := u.Files[i]
+		tracker.status(fmt.Sprintf("Hashing %d of %d files: %s", n+1, len(pending), record.Path))
+		tracker.setState(files[i], FileHashing)
+		sum, err := sha256File(record.Local)
+		if err != nil {
+			tracker.fail(files[i], err)
+			return &UploadError{Step: "hashing", File: record.Path, Err: err}
+		}
+		record.SHA256 = sum
+		files[i].sha256 = sum
+		tracker.setState(files[i], FileQueued)
+
+		if time.Since(lastSave) >= largeUploadSaveInterval {
+			if err := SaveLargeUpload(u); err != nil {
+				return fmt.Errorf("failed to save upload state: %w", err)
+			}
+			lastSave = time.Now()
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := SaveLargeUpload(u); err != nil {
+		return fmt.Errorf("failed to save upload state: %w", err)
+	}
+	return nil
+}
+
+// batches groups the indexes of files that still have to be committed into
+// commits within largeCommitFiles and largeCommitBytes.
+func (u *LargeUpload) batches() [][]int {
+	var batches [][]int
+	var current []int
+	var size int64
+	for i, record := range u.Files {
+		if record.Committed {
+			continue
+		}
+		if len(current) > 0 && (len(current) >= largeCommitFiles || size+record.Size > largeCommitBytes) {
+			batches = append(batches, current)
+			current, size = nil, 0
+		}
+		current = append(current, i)
+		size += record.Size
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// largeUploads is the saved state of all large uploads of a profile.
+type largeUploads struct {
+	Uploads []*LargeUpload `json:"uploads"`
+}
+
+func getLargeUploadsFilePath() (string, error) {
+	return profileFilePath("uploads.json")
+}
+
+// LoadLargeUploads returns the saved large uploads, oldest first.
+func LoadLargeUploads() ([]*LargeUpload, error) {
+	path, err := getLargeUploadsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var saved largeUploads
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	return saved.Uploads, nil
+}
+
+// SaveLargeUpload stores the state of upload, replacing an earlier one.
+func SaveLargeUpload(upload *LargeUpload) error {
+	uploads, err := LoadLargeUploads()
+	if err != nil {
+		return err
+	}
+	upload.UpdatedAt = time.Now()
+
+	replaced := false
+	for i, u := range uploads {
+		if u.ID == upload.ID {
+			uploads[i] = upload
+			replaced = true
+		}
+	}
+	if !replaced {
+		uploads = append(uploads, upload)
+	}
+	return saveLargeUploads(uploads)
+}
+
+// RemoveLargeUpload forgets the state of the upload with id.
+func RemoveLargeUpload(id string) error {
+	uploads, err := LoadLargeUploads()
+	if err != nil {
+		return err
+	}
+	kept := uploads[:0]
+	for _, u := range uploads {
+		if u.ID != id {
+			kept = append(kept, u)
+		}
+	}
+	return saveLargeUploads(kept)
+}
+
+// saveLargeUploads writes the uploads that are not done. A finished upload
+// has nothing left to resume, so it is dropped instead of kept forever.
+func saveLargeUploads(uploads []*LargeUpload) error {
+	var pending []*LargeUpload
+	for _, u := range uploads {
+		if !u.Done {
+			pending = append(pending, u)
+		}
+	}
+
+	path, err := getLargeUploadsFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(largeUploads{Uploads: pending}, "", " ")
+	if err != nil {
+		return err
+	}
+
+	// Written aside and renamed, so a crash never leaves a truncated state
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLargeUploadBatches(t *testing.T) {
+	files := func(sizes ...int64) []*LargeUploadFile {
+		records := make([]*LargeUploadFile, len(sizes))
+		for i, size := range sizes {
+			records[i] = &LargeUploadFile{Size: size}
+		}
+		return records
+	}
+	many := files(make([]int64, largeCommitFiles+5)...)
+	manyFirst, manyRest := make([]int, largeCommitFiles), []int{}
+	for i := range many {
+		if i < largeCommitFiles {
+			manyFirst[i] = i
+		} else {
+			manyRest = append(manyRest, i)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		files     []*LargeUploadFile
+		committed []int
+		want      [][]int
+	}{
+		{"nothing", nil, nil, nil},
+		{"one batch", files(1, 2, 3), nil, [][]int{{0, 1, 2}}},
+		{"split by count", many, nil, [][]int{manyFirst, manyRest}},
+		{"split by size", files(6<<30, 3<<30, 2<<30, 1), nil, [][]int{{0, 1}, {2, 3}}},
+		{"file above the size bound", files(1, 12<<30, 1), nil, [][]int{{0}, {1}, {2}}},
+		{"committed skipped", files(1, 2, 3, 4), []int{0, 2}, [][]int{{1, 3}}},
+		{"all committed", files(1, 2), []int{0, 1}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, record := range tt.files {
+				record.Committed = false
+			}
+			for _, i := range tt.committed {
+				tt.files[i].Committed = true
+			}
+			upload := &LargeUpload{Files: tt.files}
+			if got := upload.batches(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLargeUploadAlreadyCommitted(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/models/org/repo/paths-info/main" {
+			http.NotFound(w, r)
+			return
+		}
+		// git blob sha1 and sha256 of "hello"
+		w.Write([]byte(`[
+			{"type": "file", "path": "same.txt", "size": 5, "oid": "b6fc4c620b67d95f953a5c1c1230aaab5db5a1b0"},
+			{"type": "file", "path": "changed.txt", "size": 5, "oid": "b6fc4c620b67d95f953a5c1c1230aaab5db5a1b0"},
+			{"type": "file", "path": "model.bin", "size": 5, "oid": "x",
+			 "lfs": {"oid": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", "size": 5}},
+			{"type": "file", "path": "weights.bin", "size": 5, "oid": "x",
+			 "lfs": {"oid": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", "size": 5}},
+			{"type": "file", "path": "other.bin", "size": 5, "oid": "x",
+			 "lfs": {"oid": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", "size": 5}}
+		]`))
+	}))
+	defer server.Close()
+	client := &Client{Endpoint: server.URL, HTTPClient: server.Client(), Retry: RetryPolicy{MaxAttempts: 1}}
+
+	upload := &LargeUpload{RepoType: "model", RepoID: "org/repo", Revision: "main", Files: []*LargeUploadFile{
+		{Path: "same.txt", Local: write("same.txt", "hello"), Size: 5},
+		{Path: "changed.txt", Local: write("changed.txt", "HELLO"), Size: 5},
+		{Path: "model.bin", Local: write("model.bin", "hello"), Size: 5, SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{Path: "new.txt", Local: write("new.txt", "hello"), Size: 5},
+		{Path: "weights.bin", Local: write("weights.bin", "hello"), Size: 5},
+		{Path: "other.bin", Local: write("other.bin", "HELLO"), Size: 5},
+	}}
+	files := make([]*commitFile, len(upload.Files))
+	for i, record := range upload.Files {
+		files[i] = &commitFile{localPath: record.Local, pathInRepo: record.Path, size: record.Size}
+	}
+
+	pending, err := upload.alreadyCommitted(context.Background(), client, []int{0, 1, 2, 3, 4, 5}, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 3, 5}; !reflect.DeepEqual(pending, want) {
+		t.Errorf("alreadyCommitted() = %v, want %v", pending, want)
+	}
+	for i, want := range []bool{true, false, true, false, true, false} {
+		if upload.Files[i].Committed != want {
+			t.Errorf("%s committed = %v, want %v", upload.Files[i].Path, upload.Files[i].Committed, want)
+		}
+	}
+	// The hash computed to compare other.bin is kept for its commit
+	if want := "3733cd977ff8eb18b987357e22ced99f46097f31ecb239e878ae63760e83e4d5"; upload.Files[5].SHA256 != want || files[5].sha256 != want {
+		t.Errorf("other.bin sha256 = %q and %q, want %q", upload.Files[5].SHA256, files[5].sha256, want)
+	}
+}
@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const lfsMediaType = "application/vnd.git-lfs+json"
+
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header"`
+}
+
+type lfsObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions struct {
+		Upload *lfsAction `json:"upload"`
+		Verify *lfsAction `json:"verify"`
+	} `json:"actions"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// uploadLFSFiles pushes the content of every LFS file to storage so the
+// commit can reference it by sha256. Files the LFS server gives a chunk
+// size go up in parts, the others in a single request.
+func (c *Client) uploadLFSFiles(ctx context.Context, repoType, repoID, revision string, files []*commitFile, limiter *RateLimiter, tracker *uploadTracker) error {
+	var lfsFiles []*commitFile
+	for _, f := range files {
+		if f.uploadMode != "lfs" || f.ignore {
+			continue
+		}
+		if f.sha256 != "" {
+			lfsFiles = append(lfsFiles, f)
+			continue
+		}
+		tracker.status(fmt.Sprintf("Hashing %s...", f.pathInRepo))
+		tracker.setState(f, FileHashing)
+		sum, err := sha256File(f.localPath)
+		if err != nil {
+			tracker.fail(f, err)
+			return &UploadError{Step: "hashing", File: f.pathInRepo, Err: err}
+		}
+		f.sha256 = sum
+		tracker.setState(f, FileQueued)
+		lfsFiles = append(lfsFiles, f)
+	}
+	if len(lfsFiles) == 0 {
+		return nil
+	}
+
+	objects, err := c.lfsBatch(ctx, repoType, repoID, revision, lfsFiles)
+	if err != nil {
+		return &UploadError{Step: "uploading", Err: err}
+	}
+
+	byOID := make(map[string]*commitFile, len(lfsFiles))
+	for _, f := range lfsFiles {
+		byOID[f.sha256] = f
+	}
+	for _, obj := range objects {
+		f, ok := byOID[obj.OID]
+		if !ok {
+			continue
+		}
+		if obj.Error != nil {
+			err := fmt.Errorf("LFS error: %s", obj.Error.Message)
+			tracker.fail(f, err)
+			return &UploadError{Step: "uploading", File: f.pathInRepo, Err: err}
+		}
+		if obj.Actions.Upload == nil {
+			// Already present in storage
+			tracker.setState(f, FileUpToDate)
+			continue
+		}
+
+		tracker.status(fmt.Sprintf("Uploading %s...", f.pathInRepo))
+		upload := c.lfsUploadBasic
+		if isMultipart(obj.Actions.Upload) {
+			upload = c.lfsUploadMultipart
+		}
+		if err := upload(ctx, f, obj.Actions.Upload, limiter, tracker); err != nil {
+			tracker.fail(f, err)
+			return &UploadError{Step: "uploading", File: f.pathInRepo, Err: err}
+		}
+		if obj.Actions.Verify != nil {
+			tracker.setState(f, FileVerifying)
+			if err := c.lfsVerify(ctx, obj, obj.Actions.Verify); err != nil {
+				tracker.fail(f, err)
+				return &UploadError{Step: "uploading", File: f.pathInRepo, Err: fmt.Errorf("failed to verify: %w", err)}
+			}
+		}
+		tracker.setState(f, FileDone)
+	}
+	return nil
+}
+
+// lfsBatch negotiates upload actions for files with the repo's LFS server.
+// Even when it answers with the "multipart" transfer, only the objects whose
+// upload action has a chunk size are to be sent in parts.
+func (c *Client) lfsBatch(ctx context.Context, repoType, repoID, revision string, files []*commitFile) ([]lfsObject, error) {
+	type batchObject struct {
+		OID  string `json:"oid"`
+		Size int64  `json:"size"`
+	}
+	objects := make([]batchObject, len(files))
+	for i, f := range files {
+		objects[i] = batchObject{OID: f.sha256, Size: f.size}
+	}
+	payload := map[string]interface{}{
+		"operation": "upload",
+		"transfers": []string{"basic", "multipart"},
+		"objects":   objects,
+		"hash_algo": "sha256",
+		"ref":       map[string]string{"name": revision},
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	path := repoURLPrefix(repoType, repoID) + ".git/info/lfs/objects/batch"
+	// The batch call only negotiates, so it is safe to repeat
+	req, err := c.newRequest(withIdempotent(ctx), http.MethodPost, path, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("LFS batch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Objects []lfsObject `json:"objects"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return result.Objects, nil
+}
+
+// isMultipart reports whether action uploads its object in parts.
+func isMultipart(action *lfsAction) bool {
+	_, ok := action.Header["chunk_size"]
+	return ok
+}
+
+// lfsUploadBasic PUTs the whole file to a presigned URL. The Hub token must
+// not be sent to storage, so the request is built without it.
+func (c *Client) lfsUploadBasic(ctx context.Context, f *commitFile, action *lfsAction, limiter *RateLimiter, tracker *uploadTracker) error {
+	open := func() (io.ReadCloser, error) {
+		file, err := os.Open(f.localPath)
+		if err != nil {
+			return nil, err
+		}
+		tracker.restart(f, 0)
+		return struct {
+			io.Reader
+			io.Closer
+		}{&progressReader{throttle(ctx, file, limiter), tracker.counter(f)}, file}, nil
+	}
+	body, err := open()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, action.Href, body)
+	if err != nil {
+		body.Close()
+		return err
+	}
+	req.ContentLength = f.size
+	req.GetBody = open
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.send(c.HTTPClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHubError(resp)
+	}
+	return nil
+}
+
+// lfsPart is a finished part of a multipart upload.
+type lfsPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// lfsUploadMultipart PUTs the file in chunks to the presigned part URLs
+// listed in the action's header, then completes the upload with the ETags
+// storage returned for every part.
+func (c *Client) lfsUploadMultipart(ctx context.Context, f *commitFile, action *lfsAction, limiter *RateLimiter, tracker *uploadTracker) error {
+	chunkSize, err := strconv.ParseInt(action.Header["chunk_size"], 10, 64)
+	if err != nil || chunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size %q for multipart upload", action.Header["chunk_size"])
+	}
+
+	// Part URLs are keyed by their 1-based number, e.g. "00001"
+	partURLs := make(map[int]string)
+	for key, href := range action.Header {
+		if number, err := strconv.Atoi(key); err == nil {
+			partURLs[number] = href
+		}
+	}
+	count := int((f.size + chunkSize - 1) / chunkSize)
+	if len(partURLs) != count {
+		return fmt.Errorf("expected %d part URLs for multipart upload, got %d", count, len(partURLs))
+	}
+
+	file, err := os.Open(f.localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	parts := make([]lfsPart, 0, count)
+	for number := 1; number <= count; number++ {
+		href, ok := partURLs[number]
+		if !ok {
+			return fmt.Errorf("missing URL for part %d of multipart upload", number)
+		}
+		offset := int64(number-1) * chunkSize
+		etag, err := c.lfsUploadPart(ctx, f, file, offset, min(chunkSize, f.size-offset), href, limiter, tracker)
+		if err != nil {
+			return fmt.Errorf("failed to upload part %d of %d: %w", number, count, err)
+		}
+		parts = append(parts, lfsPart{PartNumber: number, ETag: etag})
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"oid": f.sha256, "parts": parts})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	// Completing with the same parts twice assembles the same object
+	req, err := http.NewRequestWithContext(withIdempotent(ctx), http.MethodPost, action.Href, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+
+	resp, err := c.send(c.HTTPClient, req)
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to complete multipart upload: %w", newHubError(resp))
+	}
+	return nil
+}
+
+// lfsUploadPart PUTs size bytes of file from offset to a presigned part URL
+// and returns the part's ETag.
+func (c *Client) lfsUploadPart(ctx context.Context, f *commitFile, file *os.File, offset, size int64, href string, limiter *RateLimiter, tracker *uploadTracker) (string, error) {
+	open := func() (io.ReadCloser, error) {
+		// Parts before this one are stored, so a repeat starts over from offset
+		tracker.restart(f, offset)
+		return io.NopCloser(&progressReader{throttle(ctx, io.NewSectionReader(file, offset, size), limiter), tracker.counter(f)}), nil
+	}
+	body, _ := open()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, href, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.GetBody = open
+
+	resp, err := c.send(c.HTTPClient, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", newHubError(resp)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("storage returned no ETag")
+	}
+	return etag, nil
+}
+
+func (c *Client) lfsVerify(ctx context.Context, obj lfsObject, action *lfsAction) error {
+	return c.sendJSON(withIdempotent(ctx), http.MethodPost, action.Href, map[string]interface{}{
+		"oid":  obj.OID,
+		"size": obj.Size,
+	}, nil)
+}
+
+func sha256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MirrorPlan is the difference between a local folder and a repo revision:
+// a dry run of what mirroring would write and remove.
+type MirrorPlan struct {
+	Commit     string   // Commit the revision resolved to
+	Added      []string // In the repo but missing locally
+	Changed    []string // Present locally with different content
+	UpToDate   []string
+	Extra      []string // Local files the repo does not have
+	FetchBytes int64    // Total size of Added and Changed
+}
+
+// ToFetch returns the files that have to be downloaded.
+func (p *MirrorPlan) ToFetch() []string {
+	return append(append([]string{}, p.Added...), p.Changed...)
+}
+
+// InSync reports whether the folder already matches the revision exactly.
+func (p *MirrorPlan) InSync() bool {
+	return len(p.Added)+len(p.Changed)+len(p.Extra) == 0
+}
+
+// PlanMirror compares localDir with the repo at revision without changing
+// anything.
+func PlanMirror(repoType, repoID, revision, localDir string, updateStatus func(string)) (*MirrorPlan, error) {
+	ctx := WithRetryStatus(context.Background(), updateStatus)
+	client := DefaultClient()
+
+	_, sha, err := client.ListFiles(ctx, repoType, repoID, revision)
+	if err != nil {
+		return nil, err
+	}
+	pinned := sha
+	if pinned == "" {
+		pinned = revision
+	}
+	files, err := client.ListRepoTree(ctx, repoType, repoID, pinned)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	plan := &MirrorPlan{Commit: sha}
+	remote := make(map[string]bool, len(files))
+	for i, f := range files {
+		updateStatus(fmt.Sprintf("Comparing %d/%d: %s", i+1, len(files), f.Path))
+		remote[f.Path] = true
+
+		path := filepath.Join(localDir, filepath.FromSlash(f.Path))
+		switch {
+		case !fileExists(path):
+			plan.Added = append(plan.Added, f.Path)
+		case f.ETag() != "" && upToDate(path, f.Size, f.ETag()):
+			plan.UpToDate = append(plan.UpToDate, f.Path)
+			continue
+		default:
+			plan.Changed = append(plan.Changed, f.Path)
+		}
+		plan.FetchBytes += f.Size
+	}
+
+	plan.Extra, err = extraFiles(localDir, remote)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// extraFiles returns the files under localDir that are not in remote.
+func extraFiles(localDir string, remote map[string]bool) ([]string, error) {
+	local, err := localFiles(localDir)
+	if err != nil {
+		return nil, err
+	}
+	var extra []string
+	for _, file := range local {
+		if !remote[file] {
+			extra = append(extra, file)
+		}
+	}
+	return extra, nil
+}
+
+// vcsDirs are version control folders that a mirror never touches.
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".bzr": true}
+
+// localFiles lists the files under dir as slash-separated relative paths,
+// leaving out bookkeeping: quarantined and partial files, version control
+// metadata, and what huggingface_hub keeps in .cache/huggingface of a local dir.
+func localFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == quarantineDir || vcsDirs[d.Name()] || path == filepath.Join(dir, ".cache", "huggingface") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".incomplete") {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local files: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// RemoveExtra deletes the local files the repo does not have, along with
+// folders left empty by that.
+func (p *MirrorPlan) RemoveExtra(localDir string) error {
+	for _, file := range p.Extra {
+		path := filepath.Join(localDir, filepath.FromSlash(file))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		for dir := filepath.Dir(path); dir != filepath.Clean(localDir); dir = filepath.Dir(dir) {
+			if os.Remove(dir) != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtraFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		local  []string
+		remote []string
+		want   []string
+	}{
+		{
+			name:   "git checkout",
+			local:  []string{".git/HEAD", ".git/objects/ab/cdef", "config.json"},
+			remote: []string{"config.json"},
+		},
+		{
+			name:   "other version control",
+			local:  []string{".hg/store/data", ".svn/entries", "sub/.git/config", "model.bin"},
+			remote: []string{"model.bin"},
+		},
+		{
+			name:   "bookkeeping",
+			local:  []string{".cache/huggingface/download/model.bin.metadata", "model.bin.incomplete", quarantineDir + "/model.bin"},
+			remote: nil,
+		},
+		{
+			name:   "extra files",
+			local:  []string{"config.json", "notes.txt", "old/weights.bin"},
+			remote: []string{"config.json"},
+			want:   []string{"notes.txt", "old/weights.bin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.local {
+				path := filepath.Join(dir, filepath.FromSlash(file))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			remote := make(map[string]bool)
+			for _, file := range tt.remote {
+				remote[file] = true
+			}
+
+			got, err := extraFiles(dir, remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extraFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtraFilesMissingDir(t *testing.T) {
+	got, err := extraFiles(filepath.Join(t.TempDir(), "missing"), nil)
+	if err != nil || len(got) != 0 {
+		t.Errorf("extraFiles() = %v, %v, want no files", got, err)
+	}
+}
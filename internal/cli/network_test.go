@@ -0,0 +1,33 @@
+package cli
+
+import "testing"
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		host    string
+		noProxy string
+		want    bool
+	}{
+		{"localhost", "", true},
+		{"127.0.0.1", "", true},
+		{"::1", "", true},
+		{"huggingface.co", "", false},
+		{"huggingface.co", "*", true},
+		{"huggingface.co", "huggingface.co", true},
+		{"cdn-lfs.huggingface.co", "huggingface.co", true},
+		{"cdn-lfs.huggingface.co", ".huggingface.co", true},
+		{"cdn-lfs.huggingface.co", "*.huggingface.co", true},
+		{"nothuggingface.co", "huggingface.co", false},
+		{"HuggingFace.co", "example.com, huggingface.co", true},
+		{"hub.internal", "hub.internal:8443", true},
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"11.1.2.3", "10.0.0.0/8", false},
+		{"hub.internal", "10.0.0.0/8", false},
+		{"huggingface.co", " , ", false},
+	}
+	for _, tt := range tests {
+		if got := bypassProxy(tt.host, tt.noProxy); got != tt.want {
+			t.Errorf("bypassProxy(%q, %q) = %v, want %v", tt.host, tt.noProxy, got, tt.want)
+		}
+	}
+}
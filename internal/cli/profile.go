@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultProfile is the profile that exists without any setup. Its tokens
+// and user data live directly in ~/.lazyface, as before profiles existed.
+const DefaultProfile = "default"
+
+// Profile is a Hub instance to work against. Each profile keeps its own
+// tokens, user data and download queue, so switching profiles also
+// switches accounts.
+type Profile struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint,omitempty"` // Empty means HF_ENDPOINT or the public Hub
+}
+
+// HubURL returns the endpoint the profile talks to.
+func (p Profile) HubURL() string {
+	if p.Endpoint != "" {
+		return strings.TrimRight(p.Endpoint, "/")
+	}
+	if endpoint := os.Getenv("HF_ENDPOINT"); endpoint != "" {
+		return strings.TrimRight(endpoint, "/")
+	}
+	return DefaultEndpoint
+}
+
+// ProfileData holds the known profiles and the one used at startup.
+type ProfileData struct {
+	Active   string    `json:"active"`
+	Profiles []Profile `json:"profiles"`
+}
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func getProfilesFilePath() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, ".lazyface", "profiles.json"), nil
+}
+
+// LoadProfiles reads the saved profiles. The default profile is always
+// present and listed first.
+func LoadProfiles() (*ProfileData, error) {
+	path, err := getProfilesFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles ProfileData
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := profiles.Find(DefaultProfile); !ok {
+		profiles.Profiles = append([]Profile{{Name: DefaultProfile}}, profiles.Profiles...)
+	}
+	if _, ok := profiles.Find(profiles.Active); !ok {
+		profiles.Active = DefaultProfile
+	}
+	return &profiles, nil
+}
+
+// SaveProfiles writes the profiles to disk
+func SaveProfiles(profiles *ProfileData) error {
+	path, err := getProfilesFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(profiles, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Find returns the profile called name.
+func (p *ProfileData) Find(name string) (Profile, bool) {
+	for _, profile := range p.Profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// AddProfile saves a new profile for the Hub at endpoint.
+func AddProfile(name, endpoint string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, '.', '_' and '-'", name)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q, expected e.g. https://hub.example.com", endpoint)
+	}
+
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to load profiles: %w", err)
+	}
+	if _, ok := profiles.Find(name); ok {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	profiles.Profiles = append(profiles.Profiles, Profile{Name: name, Endpoint: strings.TrimRight(endpoint, "/")})
+	return SaveProfiles(profiles)
+}
+
+var (
+	profileMu     sync.Mutex
+	activeProfile *Profile
+)
+
+// ActiveProfile returns the profile in use, initially the saved one.
+func ActiveProfile() Profile {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	if activeProfile == nil {
+		profile := Profile{Name: DefaultProfile}
+		if profiles, err := LoadProfiles(); err == nil {
+			profile, _ = profiles.Find(profiles.Active)
+		}
+		activeProfile = &profile
+	}
+	return *activeProfile
+}
+
+// SelectProfile uses the profile called name for the rest of this run
+// without changing the saved choice, e.g. for a command-line flag.
+func SelectProfile(name string) error {
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to load profiles: %w", err)
+	}
+	profile, ok := profiles.Find(name)
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	activeProfile = &profile
+	return nil
+}
+
+// SwitchProfile uses the profile called name from now on, also in later runs.
+func SwitchProfile(name string) error {
+	if err := SelectProfile(name); err != nil {
+		return err
+	}
+	profiles, err := LoadProfiles()
+	if err != nil {
+		return fmt.Errorf("failed to load profiles: %w", err)
+	}
+	profiles.Active = name
+	return SaveProfiles(profiles)
+}
+
+// Endpoint returns the Hub URL of the active profile.
+func Endpoint() string {
+	return ActiveProfile().HubURL()
+}
+
+// profileFilePath returns where the active profile keeps the file called
+// name. The default profile uses ~/.lazyface itself, others a subfolder.
+func profileFilePath(name string) (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(usr.HomeDir, ".lazyface")
+	if profile := ActiveProfile(); profile.Name != DefaultProfile {
+		dir = filepath.Join(dir, "profiles", profile.Name)
+	}
+	return filepath.Join(dir, name), nil
+}
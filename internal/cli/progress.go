@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileState is the lifecycle of a single file within a transfer.
+type FileState int
+
+const (
+	FileQueued FileState = iota
+	FileActive
+	FileVerifying
+	FileDone
+	FileFailed
+	FileSkipped
+	FileUpToDate // Already present with the same content, locally or on the Hub
+	FileHashing  // Computing the hash an upload is identified by
+)
+
+func (s FileState) String() string {
+	switch s {
+	case FileQueued:
+		return "queued"
+	case FileActive:
+		return "active"
+	case FileVerifying:
+		return "verifying"
+	case FileDone:
+		return "done"
+	case FileFailed:
+		return "failed"
+	case FileSkipped:
+		return "skipped"
+	case FileUpToDate:
+		return "up to date"
+	case FileHashing:
+		return "hashing"
+	}
+	return "unknown"
+}
+
+// FileProgress is the transfer state of one file.
+type FileProgress struct {
+	Name        string
+	State       FileState
+	BytesDone   int64
+	BytesTotal  int64 // Zero until the size is known
+	ResumedFrom int64 // Bytes recovered from an earlier partial download
+	Err         error
+}
+
+// Percent returns the completed fraction of the file in [0, 1].
+func (f FileProgress) Percent() float64 {
+	if f.State == FileDone || f.State == FileUpToDate {
+		return 1
+	}
+	if f.BytesTotal <= 0 {
+		return 0
+	}
+	return float64(f.BytesDone) / float64(f.BytesTotal)
+}
+
+// DownloadProgress is a snapshot of a whole transfer.
+type DownloadProgress struct {
+	Files      []FileProgress
+	BytesDone  int64
+	BytesTotal int64
+	Rate       float64       // Bytes per second, smoothed
+	ETA        time.Duration // Zero when unknown
+}
+
+// Percent returns the completed fraction of all bytes in [0, 1].
+func (p DownloadProgress) Percent() float64 {
+	if p.BytesTotal <= 0 {
+		return 0
+	}
+	percent := float64(p.BytesDone) / float64(p.BytesTotal)
+	if percent > 1 {
+		return 1
+	}
+	return percent
+}
+
+// Count returns how many files are in state.
+func (p DownloadProgress) Count(state FileState) int {
+	n := 0
+	for _, f := range p.Files {
+		if f.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// rateMeter turns byte counts sampled over time into a smoothed throughput.
+type rateMeter struct {
+	lastBytes int64
+	lastTime  time.Time
+	rate      float64
+}
+
+const rateSmoothing = 0.3
+
+func (r *rateMeter) sample(total int64, now time.Time) float64 {
+	if r.lastTime.IsZero() {
+		r.lastBytes, r.lastTime = total, now
+		return 0
+	}
+	elapsed := now.Sub(r.lastTime).Seconds()
+	if elapsed <= 0 {
+		return r.rate
+	}
+	current := float64(total-r.lastBytes) / elapsed
+	if current < 0 {
+		current = 0
+	}
+	if r.rate == 0 {
+		r.rate = current
+	} else {
+		r.rate = rateSmoothing*current + (1-rateSmoothing)*r.rate
+	}
+	r.lastBytes, r.lastTime = total, now
+	return r.rate
+}
+
+// progressWriter reports every write to add.
+type progressWriter struct {
+	w   io.Writer
+	add func(int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 && p.add != nil {
+		p.add(int64(n))
+	}
+	return n, err
+}
+
+// progressReader reports every read to add.
+type progressReader struct {
+	r   io.Reader
+	add func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 && p.add != nil {
+		p.add(int64(n))
+	}
+	return n, err
+}
+
+// FormatBytes renders n using binary units, e.g. "1.5 GB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// ParseBytes reads a size such as "500MB", "1.5 GiB" or "2048". Units are
+// binary, matching FormatBytes.
+func ParseBytes(input string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		if i := strings.IndexByte("KMGTPE", s[n-1]); i >= 0 {
+			multiplier = 1 << (10 * (i + 1))
+			s = s[:n-1]
+		}
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// FormatDuration renders an ETA compactly, e.g. "1h02m" or "3m15s".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
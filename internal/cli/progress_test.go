@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{5 << 20, "5.0 MB"},
+		{3 << 30, "3.0 GB"},
+		{1 << 40, "1.0 TB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"2048", 2048, false},
+		{"2048B", 2048, false},
+		{"500MB", 500 << 20, false},
+		{"500mb", 500 << 20, false},
+		{"1.5 GiB", 3 << 29, false},
+		{" 10 G ", 10 << 30, false},
+		{"1K", 1024, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"MB", 0, true},
+		{"-1MB", 0, true},
+		{"lots", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, %v, want %d (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseBytesReadsFormatBytes(t *testing.T) {
+	for _, n := range []int64{512, 1 << 10, 5 << 20, 3 << 30} {
+		got, err := ParseBytes(FormatBytes(n))
+		if err != nil || got != n {
+			t.Errorf("ParseBytes(FormatBytes(%d)) = %d, %v", n, got, err)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{42 * time.Second, "42s"},
+		{3*time.Minute + 15*time.Second, "3m15s"},
+		{time.Hour + 2*time.Minute, "1h02m"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
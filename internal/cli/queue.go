@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// JobStatus is the lifecycle of a queued download job.
+type JobStatus string
+
+const (
+	JobQueued   JobStatus = "queued"
+	JobRunning  JobStatus = "running"
+	JobDone     JobStatus = "done"
+	JobFailed   JobStatus = "failed"
+	JobCanceled JobStatus = "canceled"
+)
+
+// DownloadJob is one repo/file selection waiting in or processed by the queue.
+type DownloadJob struct {
+	ID        string    `json:"id"`
+	RepoType  string    `json:"repo_type,omitempty"` // "model", "dataset" or "space"
+	RepoID    string    `json:"repo_id"`
+	Revision  string    `json:"revision,omitempty"` // Commit SHA pinned when the job was created
+	Files     []string  `json:"files"`
+	Path      string    `json:"path"`
+	Filter    string    `json:"filter,omitempty"` // Patterns the files were selected with
+	Ref       string    `json:"ref,omitempty"`    // Branch or tag the revision was picked as
+	Cache     bool      `json:"cache,omitempty"`  // Path is a huggingface_hub cache folder
+	Status    JobStatus `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Failed    []string  `json:"failed,omitempty"` // Files that failed in the last run
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Finished reports whether the job has nothing left to run.
+func (j *DownloadJob) Finished() bool {
+	return j.Status == JobDone || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// DownloadQueue is the ordered list of download jobs persisted across runs.
+type DownloadQueue struct {
+	Jobs []*DownloadJob `json:"jobs"`
+}
+
+// Every profile has its own queue, as its jobs need its endpoint and token.
+func getQueueFilePath() (string, error) {
+	return profileFilePath("queue.json")
+}
+
+// NewDownloadJob returns a queued job for files of the repo at revision into path.
+func NewDownloadJob(repoType, repoID, revision string, files []string, path string) *DownloadJob {
+	return &DownloadJob{
+		ID:        strconv.FormatInt(time.Now().UnixNano(), 36),
+		RepoType:  repoType,
+		RepoID:    repoID,
+		Revision:  revision,
+		Files:     files,
+		Path:      path,
+		Status:    JobQueued,
+		CreatedAt: time.Now(),
+	}
+}
+
+// LoadQueue reads the saved queue. Jobs that were running when Lazyface
+// last exited are queued again so they resume from their partial files.
+func LoadQueue() (*DownloadQueue, error) {
+	path, err := getQueueFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &DownloadQueue{}, nil
+		}
+		return nil, err
+	}
+
+	var queue DownloadQueue
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, err
+	}
+	for _, job := range queue.Jobs {
+		if job.Status == JobRunning {
+			job.Status = JobQueued
+		}
+	}
+	return &queue, nil
+}
+
+// SaveQueue writes the queue to disk
+func SaveQueue(queue *DownloadQueue) error {
+	path, err := getQueueFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(queue, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func (q *DownloadQueue) Add(job *DownloadJob) {
+	q.Jobs = append(q.Jobs, job)
+}
+
+// Next returns the first job waiting to run, or nil.
+func (q *DownloadQueue) Next() *DownloadJob {
+	for _, job := range q.Jobs {
+		if job.Status == JobQueued {
+			return job
+		}
+	}
+	return nil
+}
+
+// Pending counts the jobs waiting to run.
+func (q *DownloadQueue) Pending() int {
+	n := 0
+	for _, job := range q.Jobs {
+		if job.Status == JobQueued {
+			n++
+		}
+	}
+	return n
+}
+
+// Remove drops the job at index i.
+func (q *DownloadQueue) Remove(i int) {
+	if i < 0 || i >= len(q.Jobs) {
+		return
+	}
+	q.Jobs = append(q.Jobs[:i], q.Jobs[i+1:]...)
+}
+
+// Move swaps the job at index i with its neighbour at i+delta and returns
+// the job's new index.
+func (q *DownloadQueue) Move(i, delta int) int {
+	j := i + delta
+	if i < 0 || i >= len(q.Jobs) || j < 0 || j >= len(q.Jobs) {
+		return i
+	}
+	q.Jobs[i], q.Jobs[j] = q.Jobs[j], q.Jobs[i]
+	return j
+}
+
+// ClearFinished drops every job that has nothing left to run.
+func (q *DownloadQueue) ClearFinished() {
+	jobs := q.Jobs[:0]
+	for _, job := range q.Jobs {
+		if !job.Finished() {
+			jobs = append(jobs, job)
+		}
+	}
+	q.Jobs = jobs
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// throttleChunk caps how much is read at once through a limit, so a new
+// limit takes effect quickly and the flow stays smooth.
+const throttleChunk = 32 * 1024
+
+// RateLimiter caps throughput in bytes per second. The limit can be changed
+// while transfers are running; zero means unlimited. A nil *RateLimiter
+// does not limit anything.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  int64
+	tokens float64 // Bytes that may pass without waiting, up to one second's worth
+	last   time.Time
+}
+
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	return &RateLimiter{limit: bytesPerSecond}
+}
+
+// SetLimit changes the limit, taking effect for the next bytes read.
+func (l *RateLimiter) SetLimit(bytesPerSecond int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = bytesPerSecond
+	if float64(l.limit) < l.tokens {
+		l.tokens = float64(l.limit)
+	}
+}
+
+func (l *RateLimiter) Limit() int64 {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// reserve takes n bytes from the bucket and returns how long to wait before
+// they are within the limit.
+func (l *RateLimiter) reserve(n int) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.limit <= 0 {
+		l.tokens, l.last = 0, now
+		return 0
+	}
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * float64(l.limit)
+	}
+	if l.tokens > float64(l.limit) {
+		l.tokens = float64(l.limit)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(l.limit) * float64(time.Second))
+}
+
+var (
+	globalLimiter     = NewRateLimiter(0)
+	loadGlobalLimiter sync.Once
+)
+
+// GlobalRateLimit returns the limit shared by all downloads and uploads,
+// initially the one saved in the config.
+func GlobalRateLimit() int64 {
+	return sharedLimiter().Limit()
+}
+
+// SetGlobalRateLimit changes the shared limit for running and future
+// transfers. It is not saved; see Config.MaxRate.
+func SetGlobalRateLimit(bytesPerSecond int64) {
+	sharedLimiter().SetLimit(bytesPerSecond)
+}
+
+func sharedLimiter() *RateLimiter {
+	loadGlobalLimiter.Do(func() {
+		if config, err := LoadConfig(); err == nil {
+			globalLimiter.SetLimit(config.MaxRate)
+		}
+	})
+	return globalLimiter
+}
+
+// throttledReader holds reads back to the global limit and an optional
+// per-transfer one.
+type throttledReader struct {
+	ctx      context.Context
+	r        io.Reader
+	transfer *RateLimiter
+}
+
+func throttle(ctx context.Context, r io.Reader, transfer *RateLimiter) io.Reader {
+	return &throttledReader{ctx: ctx, r: r, transfer: transfer}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+	n, err := t.r.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+
+	// Both limits are charged; waiting for the stricter one satisfies both
+	delay := max(sharedLimiter().reserve(n), t.transfer.reserve(n))
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-t.ctx.Done():
+			return n, context.Cause(t.ctx)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// GitRef is a branch or tag of a repo.
+type GitRef struct {
+	Name         string `json:"name"`
+	Ref          string `json:"ref"`
+	TargetCommit string `json:"targetCommit"`
+}
+
+// GitRefs lists the branches and tags of a repo.
+type GitRefs struct {
+	Branches []GitRef `json:"branches"`
+	Tags     []GitRef `json:"tags"`
+}
+
+func (r *GitRefs) hasBranch(name string) bool {
+	for _, b := range r.Branches {
+		if b.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GitCommit is one entry of a repo's commit history.
+type GitCommit struct {
+	ID    string    `json:"id"`
+	Title string    `json:"title"`
+	Date  time.Time `json:"date"`
+}
+
+// ListRefs returns the branches and tags of a repo.
+func (c *Client) ListRefs(ctx context.Context, repoType, repoID string) (*GitRefs, error) {
+	var refs GitRefs
+	path := fmt.Sprintf("/api/%s/%s/refs", repoTypePath(repoType), repoID)
+	if err := c.getJSON(ctx, path, &refs); err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+	return &refs, nil
+}
+
+// ListCommits returns the most recent commits reachable from revision,
+// newest first. Only the first page of the history is fetched.
+func (c *Client) ListCommits(ctx context.Context, repoType, repoID, revision string) ([]GitCommit, error) {
+	var commits []GitCommit
+	path := fmt.Sprintf("/api/%s/%s/commits/%s", repoTypePath(repoType), repoID, url.PathEscape(revision))
+	if _, err := c.getPage(ctx, path, &commits); err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	return commits, nil
+}
+
+// RepoRevisions gathers everything a user can pick from when choosing what
+// to download: branches, tags and the recent history of the default branch.
+// Retries are reported through updateStatus.
+func RepoRevisions(repoType, repoID string, updateStatus func(string)) (*GitRefs, []GitCommit, error) {
+	client := DefaultClient()
+	ctx := WithRetryStatus(context.Background(), updateStatus)
+
+	refs, err := client.ListRefs(ctx, repoType, repoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	branch := DefaultRevision
+	if !refs.hasBranch(branch) && len(refs.Branches) > 0 {
+		branch = refs.Branches[0].Name
+	}
+	commits, err := client.ListCommits(ctx, repoType, repoID, branch)
+	if err != nil {
+		return nil, nil, err
+	}
+	return refs, commits, nil
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides how often and how patiently failed Hub calls are
+// repeated. Delays grow exponentially from BaseDelay up to MaxDelay with
+// full jitter, unless the Hub asks for a specific wait with Retry-After.
+type RetryPolicy struct {
+	MaxAttempts int // Including the first one; 1 disables retries
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy is used by clients from NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// maxRetryAfter caps how long a Retry-After header can make us wait.
+const maxRetryAfter = 5 * time.Minute
+
+// backoff returns the wait before retry number attempt (1 for the first).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+type retryStatusKey struct{}
+type idempotentKey struct{}
+
+// WithRetryStatus returns a context whose Hub calls report every retry to
+// updateStatus, so views can show why an operation is taking longer.
+func WithRetryStatus(ctx context.Context, updateStatus func(string)) context.Context {
+	if updateStatus == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, retryStatusKey{}, updateStatus)
+}
+
+// withIdempotent marks calls made with ctx as safe to repeat even though
+// their method is not, e.g. POSTs that only read or negotiate.
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// idempotent reports whether sending req twice has the same effect as
+// sending it once. POST and DELETE change state on the Hub, so they are
+// only repeated when marked with withIdempotent.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut:
+		return true
+	}
+	marked, _ := req.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+// retryableStatus reports answers that may succeed when asked again.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// notSent reports network errors that happened before the request reached
+// the server, after which even a non-idempotent request can be repeated.
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter reads the wait the Hub asked for, in seconds or as a date.
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = time.Until(at)
+	}
+	return min(max(wait, 0), maxRetryAfter)
+}
+
+// send performs req with httpClient, repeating it according to the client's
+// RetryPolicy on network errors, 429 and 5xx answers. Requests with a body
+// are only repeated when the body can be replayed through req.GetBody.
+func (c *Client) send(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	policy := c.Retry
+	ctx := req.Context()
+	canReplay := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := httpClient.Do(req)
+
+		var wait time.Duration
+		var reason string
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || !(idempotent(req) || notSent(err)) {
+				return nil, err
+			}
+			reason = err.Error()
+		case retryableStatus(resp.StatusCode):
+			// A 429 was refused before it was processed, so it is always safe
+			if resp.StatusCode != http.StatusTooManyRequests && !idempotent(req) {
+				return resp, nil
+			}
+			wait = retryAfter(resp)
+			reason = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		default:
+			return resp, nil
+		}
+
+		if wait == 0 {
+			wait = policy.backoff(attempt)
+		}
+		if attempt >= policy.MaxAttempts || !canReplay {
+			return resp, err
+		}
+		// Give up with the real answer rather than run into the deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if updateStatus, ok := ctx.Value(retryStatusKey{}).(func(string)); ok {
+			updateStatus(fmt.Sprintf("Hub call failed (%s), retrying in %s (attempt %d of %d)",
+				reason, wait.Round(100*time.Millisecond), attempt+1, policy.MaxAttempts))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 500 * time.Millisecond, MaxDelay: 4 * time.Second}
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{4, 4 * time.Second},
+		{5, 4 * time.Second},
+		{80, 4 * time.Second}, // The shift overflows
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			if got := policy.backoff(tt.attempt); got <= 0 || got > tt.ceiling {
+				t.Fatalf("backoff(%d) = %v, want in (0, %v]", tt.attempt, got, tt.ceiling)
+			}
+		}
+	}
+
+	if got := (RetryPolicy{}).backoff(1); got != 0 {
+		t.Errorf("backoff without delays = %v, want 0", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		min    time.Duration
+		max    time.Duration
+	}{
+		{"missing", "", 0, 0},
+		{"seconds", "7", 7 * time.Second, 7 * time.Second},
+		{"negative", "-3", 0, 0},
+		{"capped", "3600", maxRetryAfter, maxRetryAfter},
+		{"date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+		{"past date", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+		{"garbage", "soon", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			if got := retryAfter(resp); got < tt.min || got > tt.max {
+				t.Errorf("retryAfter(%q) = %v, want between %v and %v", tt.header, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	tests := []struct {
+		code int
+		want bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusNotFound, false},
+		{http.StatusForbidden, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+		{http.StatusNotImplemented, false},
+	}
+	for _, tt := range tests {
+		if got := retryableStatus(tt.code); got != tt.want {
+			t.Errorf("retryableStatus(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		ctx      func(context.Context) context.Context
+		statuses []int
+		want     int // Status of the answer the client returns
+		calls    int
+	}{
+		{"get recovers", http.MethodGet, nil, []int{503, 502, 200}, 200, 3},
+		{"get gives up", http.MethodGet, nil, []int{500, 500, 500, 500}, 500, 3},
+		{"post is not repeated", http.MethodPost, nil, []int{503, 200}, 503, 1},
+		{"post repeated after 429", http.MethodPost, nil, []int{429, 200}, 200, 2},
+		{"idempotent post", http.MethodPost, withIdempotent, []int{503, 200}, 200, 2},
+		{"client error", http.MethodGet, nil, []int{404, 200}, 404, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			var notices []string
+			ctx := WithRetryStatus(context.Background(), func(status string) {
+				notices = append(notices, status)
+			})
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+			client := &Client{
+				Endpoint:   server.URL,
+				HTTPClient: server.Client(),
+				Retry:      RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+			}
+			req, err := client.newRequest(ctx, tt.method, "/api/whoami-v2", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.send(client.HTTPClient, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.want || calls != tt.calls {
+				t.Errorf("got %d after %d calls, want %d after %d", resp.StatusCode, calls, tt.want, tt.calls)
+			}
+			if len(notices) != calls-1 {
+				t.Errorf("got %d retry notices for %d calls", len(notices), calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// verifiedFile is a local file whose content was found to match an ETag.
+type verifiedFile struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+// verifiedFiles remembers matches by path, so planning a sync and then
+// running it hashes each unchanged file only once.
+var verifiedFiles sync.Map
+
+// upToDate reports whether the file at path already has the size and
+// content the Hub reported for it.
+func upToDate(path string, size int64, etag string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != size {
+		return false
+	}
+	key := verifiedFile{size: info.Size(), modTime: info.ModTime(), etag: etag}
+	if v, ok := verifiedFiles.Load(path); ok && v.(verifiedFile) == key {
+		return true
+	}
+	if VerifyFile(path, etag) != nil {
+		return false
+	}
+	verifiedFiles.Store(path, key)
+	return true
+}
+
+// SyncPlan splits a selection into files already present locally and
+// files that still have to be transferred.
+type SyncPlan struct {
+	UpToDate   []string
+	ToFetch    []string
+	FetchBytes int64 // Total size of ToFetch
+}
+
+// PlanSync compares files against what is already in localDir. In the
+// cache layout a file is present when its blob is.
+func PlanSync(localDir string, files []RepoFile, cacheLayout bool) SyncPlan {
+	var plan SyncPlan
+	for _, f := range files {
+		local := filepath.Join(localDir, filepath.FromSlash(f.Path))
+		if cacheLayout {
+			local = filepath.Join(localDir, filepath.FromSlash(blobPath(f.ETag())))
+		}
+		if f.ETag() != "" && upToDate(local, f.Size, f.ETag()) {
+			plan.UpToDate = append(plan.UpToDate, f.Path)
+			continue
+		}
+		plan.ToFetch = append(plan.ToFetch, f.Path)
+		plan.FetchBytes += f.Size
+	}
+	return plan
+}
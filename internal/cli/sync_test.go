@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPlanSync(t *testing.T) {
+	// git blob sha1 and sha256 of "hello"
+	const (
+		helloSHA1   = "b6fc4c620b67d95f953a5c1c1230aaab5db5a1b0"
+		helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	)
+	regular := RepoFile{Path: "config.json", Size: 5, OID: helloSHA1}
+	lfs := RepoFile{Path: "weights/model.bin", Size: 5, OID: "1111111111111111111111111111111111111111"}
+	lfs.LFS = &struct {
+		OID  string `json:"oid"`
+		Size int64  `json:"size"`
+	}{OID: helloSHA256, Size: 5}
+	noHash := RepoFile{Path: "unknown.txt", Size: 5}
+	files := []RepoFile{regular, lfs, noHash}
+
+	tests := []struct {
+		name  string
+		cache bool
+		local map[string]string // Path under the folder to content
+		want  SyncPlan
+	}{
+		{
+			name: "empty folder",
+			want: SyncPlan{ToFetch: []string{"config.json", "weights/model.bin", "unknown.txt"}, FetchBytes: 15},
+		},
+		{
+			name:  "present and unchanged",
+			local: map[string]string{"config.json": "hello", "weights/model.bin": "hello", "unknown.txt": "hello"},
+			want:  SyncPlan{UpToDate: []string{"config.json", "weights/model.bin"}, ToFetch: []string{"unknown.txt"}, FetchBytes: 5},
+		},
+		{
+			name:  "changed content",
+			local: map[string]string{"config.json": "HELLO", "weights/model.bin": "hello!"},
+			want:  SyncPlan{ToFetch: []string{"config.json", "weights/model.bin", "unknown.txt"}, FetchBytes: 15},
+		},
+		{
+			name:  "cache layout",
+			cache: true,
+			local: map[string]string{"blobs/" + helloSHA256: "hello", "config.json": "hello"},
+			want:  SyncPlan{UpToDate: []string{"weights/model.bin"}, ToFetch: []string{"config.json", "unknown.txt"}, FetchBytes: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tt.local {
+				path := filepath.Join(dir, filepath.FromSlash(file))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := PlanSync(dir, files, tt.cache); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanSync() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
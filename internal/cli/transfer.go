@@ -0,0 +1,249 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileMetadata describes a repo file as reported by the resolve endpoint.
+type FileMetadata struct {
+	Size   int64
+	ETag   string // sha256 for LFS files, git blob sha1 otherwise
+	Commit string // Commit the revision resolved to
+}
+
+func resolvePath(repoType, repoID, revision, file string) string {
+	return fmt.Sprintf("%s/resolve/%s/%s", repoURLPrefix(repoType, repoID), url.PathEscape(revision), escapePath(file))
+}
+
+// FileMetadata issues a HEAD request for file without following the redirect
+// to storage, which is where the Hub reports LFS sizes and hashes.
+func (c *Client) FileMetadata(ctx context.Context, repoType, repoID, revision, file string) (*FileMetadata, error) {
+	ctx, cancel := c.apiContext(ctx)
+	defer cancel()
+
+	noRedirect := *c.HTTPClient
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	path := resolvePath(repoType, repoID, revision, file)
+	// Relative redirects happen for renamed repos and are followed by hand
+	for hops := 0; hops < 5; hops++ {
+		req, err := c.newRequest(ctx, http.MethodHead, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept-Encoding", "identity")
+
+		resp, err := c.send(&noRedirect, req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 && resp.StatusCode <= 399 && resp.Header.Get("X-Linked-Size") == "" {
+			location := resp.Header.Get("Location")
+			if location == "" || !strings.HasPrefix(location, "/") {
+				return nil, fmt.Errorf("unexpected redirect for %s", file)
+			}
+			path = location
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return nil, newHubError(resp)
+		}
+
+		meta := &FileMetadata{
+			Size:   resp.ContentLength,
+			ETag:   normalizeETag(resp.Header.Get("ETag")),
+			Commit: resp.Header.Get("X-Repo-Commit"),
+		}
+		if linked := resp.Header.Get("X-Linked-Size"); linked != "" {
+			if size, err := strconv.ParseInt(linked, 10, 64); err == nil {
+				meta.Size = size
+			}
+		}
+		if linked := resp.Header.Get("X-Linked-Etag"); linked != "" {
+			meta.ETag = normalizeETag(linked)
+		}
+		return meta, nil
+	}
+	return nil, fmt.Errorf("too many redirects for %s", file)
+}
+
+func normalizeETag(etag string) string {
+	return strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+}
+
+// partialPath is where an in-progress download of dest is kept. Encoding
+// the remote ETag in the name means a partial file is only ever resumed
+// against the exact content it was started from.
+func partialPath(dest, etag string) string {
+	if etag == "" {
+		return dest + ".incomplete"
+	}
+	return dest + "." + etag + ".incomplete"
+}
+
+// PartialSize returns how many bytes of file are already on disk from an
+// interrupted download with the same ETag.
+func PartialSize(localDir, file, etag string) int64 {
+	if etag == "" {
+		return 0
+	}
+	info, err := os.Stat(partialPath(filepath.Join(localDir, filepath.FromSlash(file)), etag))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// DownloadFile fetches a single repo file into localDir, keeping its path
+// inside the repo. Data is written to a partial file that is resumed with a
+// Range request on the next attempt when etag matches, and the file only
+// appears at its final path once complete. onBytes, if set, is called as
+// new data is written.
+func (c *Client) DownloadFile(ctx context.Context, repoType, repoID, revision, file, localDir, etag string, onBytes func(int64)) error {
+	return c.downloadTo(ctx, repoType, repoID, revision, file, filepath.Join(localDir, filepath.FromSlash(file)), etag, nil, onBytes)
+}
+
+// downloadTo is DownloadFile writing to an arbitrary dest path, limited to
+// the global rate limit and limiter.
+func (c *Client) downloadTo(ctx context.Context, repoType, repoID, revision, file, dest, etag string, limiter *RateLimiter, onBytes func(int64)) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp := partialPath(dest, etag)
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer out.Close()
+
+	var offset int64
+	if etag != "" {
+		if offset, err = out.Seek(0, io.SeekEnd); err != nil {
+			return fmt.Errorf("failed to read partial file: %w", err)
+		}
+	} else if err := out.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, resolvePath(repoType, repoID, revision, file), nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// If the revision now points at other content, the Hub sends the
+		// whole new file instead of appending it to the old bytes
+		req.Header.Set("If-Range", `"`+etag+`"`)
+	}
+
+	resp, err := c.send(c.HTTPClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file already holds everything
+		return finishPartial(out, tmp, dest)
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start := contentRangeStart(resp.Header.Get("Content-Range")); start != offset {
+			return fmt.Errorf("server resumed at byte %d, expected %d", start, offset)
+		}
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		// Range was not honoured or the content changed, start over
+		if offset > 0 {
+			if err := out.Truncate(0); err != nil {
+				return fmt.Errorf("failed to write file: %w", err)
+			}
+			if _, err := out.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to write file: %w", err)
+			}
+		}
+	default:
+		return newHubError(resp)
+	}
+
+	if _, err := io.Copy(&progressWriter{w: out, add: onBytes}, throttle(ctx, resp.Body, limiter)); err != nil {
+		if etag == "" {
+			out.Close()
+			os.Remove(tmp)
+		}
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return finishPartial(out, tmp, dest)
+}
+
+// finishPartial closes a completed partial file and moves it into place.
+func finishPartial(out *os.File, tmp, dest string) error {
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return err
+	}
+	// Partials for content that has since changed on the Hub are stale now
+	removePartials(dest)
+	return nil
+}
+
+// RemovePartials deletes the partial files kept for files in localDir, e.g.
+// when a canceled download will not be resumed. In the cache layout
+// partials are named after their blob rather than the file, so every
+// partial blob of the repo is removed.
+func RemovePartials(localDir string, files []string, cacheLayout bool) {
+	if cacheLayout {
+		entries, err := os.ReadDir(filepath.Join(localDir, "blobs"))
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".incomplete") {
+				os.Remove(filepath.Join(localDir, "blobs", entry.Name()))
+			}
+		}
+		return
+	}
+	for _, file := range files {
+		removePartials(filepath.Join(localDir, filepath.FromSlash(file)))
+	}
+}
+
+// removePartials deletes every partial file of dest, whatever its ETag.
+func removePartials(dest string) {
+	dir, base := filepath.Split(dest)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, base+".") && strings.HasSuffix(name, ".incomplete") {
+			os.Remove(filepath.Join(dir, name))
+		}
+	}
+}
+
+// contentRangeStart parses the first byte position of "bytes start-end/size".
+func contentRangeStart(header string) int64 {
+	header = strings.TrimPrefix(header, "bytes ")
+	if i := strings.IndexByte(header, '-'); i > 0 {
+		if start, err := strconv.ParseInt(header[:i], 10, 64); err == nil {
+			return start
+		}
+	}
+	return -1
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadResume(t *testing.T) {
+	const content = "hello world"
+	tests := []struct {
+		name    string
+		etag    string // ETag of the partial file
+		partial string
+		want    string
+	}{
+		{"same content", "abc", "hello", content},
+		{"content changed", "old", "HELLO", content},
+		{"no partial", "abc", "", content},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var start int
+				if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err == nil && ifRange(r, `"abc"`) {
+					w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+					w.WriteHeader(http.StatusPartialContent)
+					w.Write([]byte(content[start:]))
+					return
+				}
+				w.Write([]byte(content))
+			}))
+			defer server.Close()
+			client := &Client{Endpoint: server.URL, HTTPClient: server.Client(), Retry: RetryPolicy{MaxAttempts: 1}}
+
+			dir := t.TempDir()
+			dest := filepath.Join(dir, "file.txt")
+			if tt.partial != "" {
+				if err := os.WriteFile(partialPath(dest, tt.etag), []byte(tt.partial), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := client.DownloadFile(context.Background(), "model", "org/repo", "main", "file.txt", dir, tt.etag, nil); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("downloaded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// ifRange reports whether a Range request may be served for content with
+// etag, which is the case without If-Range or when it matches.
+func ifRange(r *http.Request, etag string) bool {
+	value := r.Header.Get("If-Range")
+	return value == "" || value == etag
+}
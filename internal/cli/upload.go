@@ -1,45 +1,146 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
+// CommitInfo identifies the commit an upload created.
+type CommitInfo struct {
+	SHA string `json:"sha"`
+	URL string `json:"url"`
+}
+
 // UploadToHuggingFace uploads files to a Hugging Face repository with advanced options.
-// It uses a wrapper function for executing Hugging Face CLI commands.
-func UploadToHuggingFace(repoID, localPath, pathInRepo string, repoType string, includePattern, excludePattern, deletePattern, commitMessage, revision string) (string, error) {
+// Everything selected is pushed through the Hub's commit API as a single commit.
+// Besides the global rate limit, the upload is held to limiter when it is set.
+// Each step and the bytes sent per file are reported like a download's. It
+// returns the commit that was created; failures are an *UploadError naming
+// the step and file.
+func UploadToHuggingFace(repoID, localPath, pathInRepo string, repoType string, includePattern, excludePattern, deletePattern, commitMessage, revision string, limiter *RateLimiter, updateStatus func(string), updateProgress func(DownloadProgress)) (*CommitInfo, error) {
 	// Validate that repoID, localPath, and pathInRepo are not empty
 	if repoID == "" || localPath == "" || pathInRepo == "" {
-		return "", fmt.Errorf("repo ID, local path, and path in repo cannot be empty")
+		return nil, fmt.Errorf("repo ID, local path, and path in repo cannot be empty")
+	}
+	if repoType == "" {
+		repoType = "model"
+	}
+	if revision == "" {
+		revision = "main"
+	}
+	if commitMessage == "" {
+		commitMessage = "Upload with Lazyface"
+	}
+
+	files, err := collectUploadFiles(localPath, pathInRepo, splitPatterns(includePattern), splitPatterns(excludePattern))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local files: %v", err)
 	}
 
-	// Build the command arguments
-	args := []string{"upload", repoID, localPath, pathInRepo}
+	tracker := newUploadTracker(updateStatus, updateProgress)
+	ctx := WithRetryStatus(context.Background(), tracker.updateStatus)
+	client := DefaultClient()
 
-	// Add optional flags if provided
-	if repoType != "" {
-		args = append(args, "--repo-type", repoType)
+	var deletions []string
+	if deletePattern != "" {
+		deletions, err = client.filesToDelete(ctx, repoType, repoID, revision, pathInRepo, splitPatterns(deletePattern), files)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload files to Hugging Face: %w", &UploadError{Step: "preparing", Err: err})
+		}
 	}
-	if includePattern != "" {
-		args = append(args, "--include", includePattern)
+	if len(files) == 0 && len(deletions) == 0 {
+		return nil, fmt.Errorf("no files matched, nothing to upload")
 	}
-	if excludePattern != "" {
-		args = append(args, "--exclude", excludePattern)
+
+	stop := tracker.start()
+	commit, err := client.createCommit(ctx, repoType, repoID, revision, commitMessage, files, deletions, limiter, tracker)
+	stop()
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload files to Hugging Face: %w", err)
 	}
-	if deletePattern != "" {
-		args = append(args, "--delete", deletePattern)
+
+	return &CommitInfo{SHA: commit.CommitOID, URL: commit.CommitURL}, nil
+}
+
+// collectUploadFiles maps localPath onto repo paths. A single file is
+// uploaded as pathInRepo; a folder is uploaded below pathInRepo.
+func collectUploadFiles(localPath, pathInRepo string, include, exclude []string) ([]*commitFile, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, err
 	}
-	if commitMessage != "" {
-		args = append(args, "--commit-message", commitMessage)
+	if !info.IsDir() {
+		return []*commitFile{{localPath: localPath, pathInRepo: strings.Trim(pathInRepo, "/")}}, nil
 	}
-	if revision != "" {
-		args = append(args, "--revision", revision)
+
+	prefix := strings.Trim(pathInRepo, "/")
+	if prefix == "." {
+		prefix = ""
 	}
 
-	// Use your wrapper function to execute the Hugging Face CLI command
-	_, err := RunCommand(args...)
+	var files []*commitFile
+	err = filepath.WalkDir(localPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || rel == ".cache/huggingface" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(include) > 0 && !matchAny(include, rel) {
+			return nil
+		}
+		if matchAny(exclude, rel) {
+			return nil
+		}
+		files = append(files, &commitFile{localPath: p, pathInRepo: path.Join(prefix, rel)})
+		return nil
+	})
+	return files, err
+}
+
+// filesToDelete lists remote files below pathInRepo that match the delete
+// patterns and are not being re-uploaded in the same commit.
+func (c *Client) filesToDelete(ctx context.Context, repoType, repoID, revision, pathInRepo string, patterns []string, uploads []*commitFile) ([]string, error) {
+	remote, _, err := c.ListFiles(ctx, repoType, repoID, revision)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload files to Hugging Face: %v", err)
+		return nil, err
+	}
+
+	uploading := make(map[string]bool, len(uploads))
+	for _, f := range uploads {
+		uploading[f.pathInRepo] = true
 	}
 
-	return "SUCCESS", nil
+	prefix := strings.Trim(pathInRepo, "/")
+	if prefix == "." {
+		prefix = ""
+	}
+
+	var deletions []string
+	for _, file := range remote {
+		rel := file
+		if prefix != "" {
+			if !strings.HasPrefix(file, prefix+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(file, prefix+"/")
+		}
+		if matchAny(patterns, rel) && !uploading[file] {
+			deletions = append(deletions, file)
+		}
+	}
+	return deletions, nil
 }
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// UploadError tells which step of an upload failed, and for which file.
+type UploadError struct {
+	Step string // "preparing", "hashing", "uploading" or "committing"
+	File string // Path in the repo; empty when the step is not about one file
+	Err  error
+}
+
+func (e *UploadError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s %s: %v", e.Step, e.File, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// uploadTracker follows the files of an upload and reports their progress
+// the same way Download does. A nil tracker ignores everything.
+type uploadTracker struct {
+	updateStatus   func(string)
+	updateProgress func(DownloadProgress)
+	prefix         string // Put before every status, e.g. the part of a large upload
+
+	mu    sync.Mutex
+	files []FileProgress
+	index map[string]int // Path in the repo to its entry in files
+	sent  int64          // Bytes that went out, including repeats, for the rate
+	meter rateMeter
+}
+
+func newUploadTracker(updateStatus func(string), updateProgress func(DownloadProgress)) *uploadTracker {
+	if updateStatus == nil {
+		updateStatus = func(string) {}
+	}
+	if updateProgress == nil {
+		updateProgress = func(DownloadProgress) {}
+	}
+	return &uploadTracker{updateStatus: updateStatus, updateProgress: updateProgress, index: make(map[string]int)}
+}
+
+// track adds files, or updates their sizes once they are known.
+func (t *uploadTracker) track(files []*commitFile) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range files {
+		i, ok := t.index[f.pathInRepo]
+		if !ok {
+			i = len(t.files)
+			t.index[f.pathInRepo] = i
+			t.files = append(t.files, FileProgress{Name: f.pathInRepo})
+		}
+		t.files[i].BytesTotal = f.size
+	}
+}
+
+func (t *uploadTracker) status(status string) {
+	if t != nil {
+		t.updateStatus(t.prefix + status)
+	}
+}
+
+// setState moves f to state. Files that are stored already count as fully
+// sent, skipped ones no longer count at all.
+func (t *uploadTracker) setState(f *commitFile, state FileState) {
+	t.update(f, func(p *FileProgress) {
+		p.State = state
+		switch state {
+		case FileDone, FileUpToDate:
+			p.BytesDone = p.BytesTotal
+		case FileSkipped:
+			p.BytesDone, p.BytesTotal = 0, 0
+		}
+	})
+}
+
+func (t *uploadTracker) fail(f *commitFile, err error) {
+	t.update(f, func(p *FileProgress) {
+		p.State = FileFailed
+		p.Err = err
+	})
+}
+
+// restart sets how much of f is stored before a (repeated) attempt sends
+// more, so bytes that are sent again are not counted twice.
+func (t *uploadTracker) restart(f *commitFile, done int64) {
+	t.update(f, func(p *FileProgress) {
+		p.State = FileActive
+		p.BytesDone = done
+	})
+}
+
+// add records n bytes of f going out; f is nil for the commit itself.
+func (t *uploadTracker) add(f *commitFile, n int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sent += n
+	if f == nil {
+		return
+	}
+	if i, ok := t.index[f.pathInRepo]; ok {
+		t.files[i].BytesDone += n
+	}
+}
+
+// counter returns a callback for progressReader that adds to f.
+func (t *uploadTracker) counter(f *commitFile) func(int64) {
+	return func(n int64) {
+		t.add(f, n)
+	}
+}
+
+func (t *uploadTracker) update(f *commitFile, change func(*FileProgress)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if i, ok := t.index[f.pathInRepo]; ok {
+		change(&t.files[i])
+	}
+}
+
+// report sends a snapshot of the current progress to updateProgress.
+func (t *uploadTracker) report() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	progress := DownloadProgress{Files: make([]FileProgress, len(t.files))}
+	copy(progress.Files, t.files)
+	for _, f := range t.files {
+		progress.BytesDone += f.BytesDone
+		progress.BytesTotal += f.BytesTotal
+	}
+	progress.Rate = t.meter.sample(t.sent, time.Now())
+	t.mu.Unlock()
+
+	if progress.Rate > 0 && progress.BytesTotal > progress.BytesDone {
+		progress.ETA = time.Duration(float64(progress.BytesTotal-progress.BytesDone) / progress.Rate * float64(time.Second))
+	}
+	t.updateProgress(progress)
+}
+
+// start reports progress every progressInterval until the returned func is
+// called, which sends a last report.
+func (t *uploadTracker) start() (stop func()) {
+	if t == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.report()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		t.report()
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// quarantineDir is where corrupt downloads are moved, relative to the
+// download directory.
+const quarantineDir = ".lazyface-quarantine"
+
+var (
+	sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	sha1Pattern   = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// ChecksumError reports a file whose content does not match the Hub.
+type ChecksumError struct {
+	File        string
+	Expected    string
+	Actual      string
+	Quarantined string // Where the corrupt file was moved, if it was
+}
+
+func (e *ChecksumError) Error() string {
+	msg := fmt.Sprintf("checksum mismatch for %s: expected %s, got %s", e.File, shortHash(e.Expected), shortHash(e.Actual))
+	if e.Quarantined != "" {
+		msg += fmt.Sprintf(" (moved to %s)", e.Quarantined)
+	}
+	return msg
+}
+
+func shortHash(h string) string {
+	if len(h) > 12 {
+		return h[:12]
+	}
+	return h
+}
+
+// VerifyFile checks the file at path against the ETag the Hub reported for
+// it: a sha256 for LFS files or a git blob sha1 for regular ones. ETags in
+// any other format cannot be checked and are accepted.
+func VerifyFile(path, etag string) error {
+	var h hash.Hash
+	switch {
+	case sha256Pattern.MatchString(etag):
+		h = sha256.New()
+	case sha1Pattern.MatchString(etag):
+		h = sha1.New()
+	default:
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if len(etag) == 40 {
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		// Git hashes blobs with a "blob <size>\0" header
+		io.WriteString(h, "blob "+strconv.FormatInt(info.Size(), 10)+"\x00")
+	}
+	if _, err := io.Copy(h, file); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != etag {
+		return &ChecksumError{File: path, Expected: etag, Actual: actual}
+	}
+	return nil
+}
+
+// quarantine moves a corrupt file out of the way so it is never mistaken for
+// a good copy, returning its new location. Blobs of the cache layout go to
+// ~/.lazyface/quarantine instead, keeping the cache free of foreign folders.
+func quarantine(localDir, file string, cacheLayout bool) (string, error) {
+	dir := filepath.Join(localDir, quarantineDir)
+	if cacheLayout {
+		usr, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(usr.HomeDir, ".lazyface", "quarantine", filepath.Base(localDir))
+	}
+	target := filepath.Join(dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(filepath.Join(localDir, filepath.FromSlash(file)), target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// VerifyStatus is the outcome of checking one local file.
+type VerifyStatus int
+
+const (
+	VerifyOK VerifyStatus = iota
+	VerifyMismatch
+	VerifyMissing
+)
+
+func (s VerifyStatus) String() string {
+	switch s {
+	case VerifyOK:
+		return "ok"
+	case VerifyMismatch:
+		return "mismatch"
+	case VerifyMissing:
+		return "missing"
+	}
+	return "unknown"
+}
+
+// VerifyResult is the outcome of checking one repo file against localDir.
+type VerifyResult struct {
+	File   string
+	Status VerifyStatus
+	Err    error
+}
+
+// VerifyLocal checks every file of the repo at revision against its copy in
+// localDir. Nothing is modified; mismatches and missing files are reported.
+func VerifyLocal(repoType, repoID, revision, localDir string, updateStatus func(string)) ([]VerifyResult, error) {
+	ctx := WithRetryStatus(context.Background(), updateStatus)
+	files, err := DefaultClient().ListRepoTree(ctx, repoType, repoID, revision)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	results := make([]VerifyResult, 0, len(files))
+	for i, file := range files {
+		updateStatus(fmt.Sprintf("Verifying %d/%d: %s", i+1, len(files), file.Path))
+
+		result := VerifyResult{File: file.Path}
+		path := filepath.Join(localDir, filepath.FromSlash(file.Path))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			result.Status = VerifyMissing
+		} else if err := VerifyFile(path, file.ETag()); err != nil {
+			result.Status = VerifyMismatch
+			result.Err = err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
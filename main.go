@@ -3,6 +3,7 @@ package main
 import (
 	"Lazyface/cmd"
 	"Lazyface/internal/cli"
+	"flag"
 	"fmt"
 	"os"
 	"time"
@@ -66,10 +67,22 @@ func (m model) Init() tea.Cmd {
 			return tickMsg(t)
 		})
 	}
-	return nil
+	return m.initViews()
+}
+
+// initViews runs the Init command of every main view, e.g. so the Download
+// view can pick up jobs left in its queue.
+func (m model) initViews() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, view := range m.views {
+		cmds = append(cmds, view.Init())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var initCmd tea.Cmd
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -78,12 +91,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.isAuthenticated = true
 				m.showSplash = false
 				m.loadMainViews()
+				initCmd = m.initViews()
 			}
 		case "n": // User skips login
 			if m.showSplash {
 				m.isAuthenticated = false
 				m.showSplash = false
 				m.loadMainViews()
+				initCmd = m.initViews()
 			}
 		case "tab":
 			if !m.showSplash {
@@ -98,6 +113,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		mainContentStyle = mainContentStyle.Width(m.width - 4)
 		m.navigationUI.Width = m.width // Update navigation width dynamically
+	case cmd.SwitchProfileMsg:
+		if err := m.switchProfile(msg.Profile); err != nil {
+			return m, func() tea.Msg { return cmd.ProfileSwitchFailedMsg{Err: err} }
+		}
+		return m, m.initViews()
 	case tickMsg:
 		if m.showAnimation {
 			m.showAnimation = false
@@ -106,15 +126,50 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	if !m.showSplash {
-		updatedView, cmd := m.views[m.activeView].Update(msg)
-		m.views[m.activeView] = updatedView
 		m.navigationUI.ActiveView = m.activeView
-		return m, cmd
+
+		// Keys belong to the visible view; everything else (download
+		// progress, completions) goes to every view so work started in one
+		// keeps running while another is shown.
+		if _, ok := msg.(tea.KeyMsg); ok {
+			updatedView, cmd := m.views[m.activeView].Update(msg)
+			m.views[m.activeView] = updatedView
+			return m, tea.Batch(initCmd, cmd)
+		}
+
+		cmds := []tea.Cmd{initCmd}
+		for i, view := range m.views {
+			updatedView, cmd := view.Update(msg)
+			m.views[i] = updatedView
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
 	}
 
 	return m, nil
 }
 
+// switchProfile makes profile active and reloads the views for its account,
+// queue and endpoint. Views are replaced, so it is refused while one of them
+// is still transferring files.
+func (m *model) switchProfile(profile cli.Profile) error {
+	for _, view := range m.views {
+		if busy, ok := view.(cmd.BusyView); ok && busy.Busy() {
+			return fmt.Errorf("a transfer is running, wait for it to finish or cancel it before switching profiles")
+		}
+	}
+	if err := cli.SwitchProfile(profile.Name); err != nil {
+		return err
+	}
+
+	_, err := cli.LoadUserData()
+	m.isAuthenticated = m.isAuthenticated || err == nil
+	m.loadMainViews()
+	m.activeView = len(m.views) - 1 // Stay on Settings
+	m.navigationUI.ActiveView = m.activeView
+	return nil
+}
+
 func (m *model) loadMainViews() {
 	var views []tea.Model
 	var viewNames []string
@@ -127,9 +182,7 @@ func (m *model) loadMainViews() {
 		if m.hasUserData {
 			views = append(views, cmd.InitialUploadModel(), cmd.InitialManageModel(), cmd.InitialDownloadModel())
 
-			settingsModel, _ := cmd.InitialSettingsModel()
-			views = append(views, settingsModel)
-			viewNames = append(viewNames, "Upload", "Manage", "Download", "Settings")
+			viewNames = append(viewNames, "Upload", "Manage", "Download")
 		} else {
 			views = append(views, cmd.NewAuthView(), cmd.InitialUploadModel(), cmd.InitialManageModel(), cmd.InitialDownloadModel())
 			viewNames = append(viewNames, "Auth", "Upload", "Manage", "Download")
@@ -139,6 +192,11 @@ func (m *model) loadMainViews() {
 		viewNames = append(viewNames, "Download", "Auth")
 	}
 
+	// Settings is always last, it is where profiles are switched
+	settingsModel, _ := cmd.InitialSettingsModel()
+	views = append(views, settingsModel)
+	viewNames = append(viewNames, "Settings")
+
 	m.views = views
 	m.activeView = 0
 	m.navigationUI.ViewNames = viewNames
@@ -161,6 +219,25 @@ func (m model) View() string {
 }
 
 func main() {
+	profile := flag.String("profile", "", "profile to use for this run instead of the saved one")
+	flag.Parse()
+	if *profile != "" {
+		if err := cli.SelectProfile(*profile); err != nil {
+			fmt.Println("Error selecting the profile:", err)
+			os.Exit(1)
+		}
+	}
+
+	config, err := cli.LoadConfig()
+	if err != nil {
+		fmt.Println("Error loading the config:", err)
+		os.Exit(1)
+	}
+	if err := cli.ConfigureNetwork(config); err != nil {
+		fmt.Println("Error in the network settings:", err)
+		os.Exit(1)
+	}
+
 	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
 	if err := p.Start(); err != nil {
 		fmt.Println("Error starting the TUI:", err)